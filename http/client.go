@@ -1,5 +1,6 @@
 package http
 
+import "github.com/humpback/gounits/httpx"
 import "golang.org/x/net/proxy"
 
 import (
@@ -11,18 +12,9 @@ import (
 )
 
 type HttpClient struct {
-	c                   *http.Client
-	tlsconfig           *tls.Config
+	c                   *httpx.HttpClient
 	tlshandshaketimeout time.Duration
 	proxy               *url.URL
-	auth                basicAuth
-	cookies             []*http.Cookie
-	headers             map[string]string
-}
-
-type basicAuth struct {
-	username string
-	password string
 }
 
 var DefaultClient = NewClient()
@@ -37,12 +29,18 @@ func NewClient() *HttpClient {
 
 func NewWithClient(client *http.Client) *HttpClient {
 
+	return NewWithHttpx(httpx.NewWithClient(client))
+}
+
+func NewWithHttpx(client *httpx.HttpClient) *HttpClient {
+
+	if client == nil {
+		client = httpx.NewClient()
+	}
+
 	return &HttpClient{
 		c:                   client,
 		tlshandshaketimeout: time.Second * 35,
-		auth:                basicAuth{},
-		cookies:             make([]*http.Cookie, 0),
-		headers:             make(map[string]string),
 	}
 }
 
@@ -68,19 +66,20 @@ func NewWithTimeout(timeout time.Duration) *HttpClient {
 	return client.SetTransport(transport)
 }
 
+func (client *HttpClient) Httpx() *httpx.HttpClient {
+
+	return client.c
+}
+
 func (client *HttpClient) Close() {
 
-	if client.c != nil {
-		if transport, ok := client.c.Transport.(*http.Transport); ok {
-			transport.CloseIdleConnections()
-		}
-	}
+	client.c.Close()
 }
 
 func (client *HttpClient) GetTransport() *http.Transport {
 
-	if client.c != nil {
-		if transport, ok := client.c.Transport.(*http.Transport); ok {
+	if c := client.c.RawClient(); c != nil {
+		if transport, ok := c.Transport.(*http.Transport); ok {
 			return transport
 		}
 	}
@@ -89,9 +88,7 @@ func (client *HttpClient) GetTransport() *http.Transport {
 
 func (client *HttpClient) SetTransport(transport *http.Transport) *HttpClient {
 
-	if client.c != nil {
-		client.c.Transport = transport
-	}
+	client.c.SetTransport(transport)
 	return client
 }
 
@@ -145,33 +142,36 @@ func (client *HttpClient) SetTLSHandshakeTimeout(timeout time.Duration) *HttpCli
 
 func (client *HttpClient) SetBasicAuth(username string, password string) *HttpClient {
 
-	client.auth.username = username
-	client.auth.password = password
+	client.c.SetBasicAuth(username, password)
 	return client
 }
 
 func (client *HttpClient) SetHeader(key string, value string) *HttpClient {
 
-	client.headers[key] = value
+	client.c.SetHeader(key, value)
 	return client
 }
 
 func (client *HttpClient) SetHeaders(headers map[string]string) *HttpClient {
 
-	for key, value := range headers {
-		client.headers[key] = value
-	}
+	client.c.SetHeaders(headers)
 	return client
 }
 
 func (client *HttpClient) SetCookie(cookie *http.Cookie) *HttpClient {
 
-	client.cookies = append(client.cookies, cookie)
+	client.c.SetCookie(cookie)
 	return client
 }
 
 func (client *HttpClient) SetCookies(cookies []*http.Cookie) *HttpClient {
 
-	client.cookies = append(client.cookies, cookies...)
+	client.c.SetCookies(cookies)
+	return client
+}
+
+func (client *HttpClient) SetMaxBodySize(size int64) *HttpClient {
+
+	client.c.SetMaxBodySize(size)
 	return client
 }
@@ -1,219 +1,354 @@
 package http
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"io"
-	"net/http"
 	"net/url"
 )
 
 func Head(path string, query url.Values, headers map[string][]string) (*Response, error) {
 
-	return DefaultClient.Head(path, query, headers)
+	return DefaultClient.HeadContext(context.Background(), path, query, headers)
+}
+
+func HeadContext(ctx context.Context, path string, query url.Values, headers map[string][]string) (*Response, error) {
+
+	return DefaultClient.HeadContext(ctx, path, query, headers)
 }
 
 func Options(path string, query url.Values, headers map[string][]string) (*Response, error) {
 
-	return DefaultClient.Options(path, query, headers)
+	return DefaultClient.OptionsContext(context.Background(), path, query, headers)
+}
+
+func OptionsContext(ctx context.Context, path string, query url.Values, headers map[string][]string) (*Response, error) {
+
+	return DefaultClient.OptionsContext(ctx, path, query, headers)
 }
 
 func Get(path string, query url.Values, headers map[string][]string) (*Response, error) {
 
-	return DefaultClient.Get(path, query, headers)
+	return DefaultClient.GetContext(context.Background(), path, query, headers)
+}
+
+func GetContext(ctx context.Context, path string, query url.Values, headers map[string][]string) (*Response, error) {
+
+	return DefaultClient.GetContext(ctx, path, query, headers)
 }
 
 func Post(path string, query url.Values, body io.Reader, headers map[string][]string) (*Response, error) {
 
-	return DefaultClient.Post(path, query, body, headers)
+	return DefaultClient.PostContext(context.Background(), path, query, body, headers)
+}
+
+func PostContext(ctx context.Context, path string, query url.Values, body io.Reader, headers map[string][]string) (*Response, error) {
+
+	return DefaultClient.PostContext(ctx, path, query, body, headers)
 }
 
 func PostJSON(path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
 
-	return DefaultClient.PostJSON(path, query, object, headers)
+	return DefaultClient.PostJSONContext(context.Background(), path, query, object, headers)
+}
+
+func PostJSONContext(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
+
+	return DefaultClient.PostJSONContext(ctx, path, query, object, headers)
+}
+
+func PostXML(path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
+
+	return DefaultClient.PostXMLContext(context.Background(), path, query, object, headers)
+}
+
+func PostXMLContext(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
+
+	return DefaultClient.PostXMLContext(ctx, path, query, object, headers)
+}
+
+func PostYAML(path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
+
+	return DefaultClient.PostYAMLContext(context.Background(), path, query, object, headers)
+}
+
+func PostYAMLContext(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
+
+	return DefaultClient.PostYAMLContext(ctx, path, query, object, headers)
 }
 
 func Put(path string, query url.Values, body io.Reader, headers map[string][]string) (*Response, error) {
 
-	return DefaultClient.Put(path, query, body, headers)
+	return DefaultClient.PutContext(context.Background(), path, query, body, headers)
+}
+
+func PutContext(ctx context.Context, path string, query url.Values, body io.Reader, headers map[string][]string) (*Response, error) {
+
+	return DefaultClient.PutContext(ctx, path, query, body, headers)
 }
 
 func PutJSON(path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
 
-	return DefaultClient.PutJSON(path, query, object, headers)
+	return DefaultClient.PutJSONContext(context.Background(), path, query, object, headers)
+}
+
+func PutJSONContext(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
+
+	return DefaultClient.PutJSONContext(ctx, path, query, object, headers)
+}
+
+func PutXML(path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
+
+	return DefaultClient.PutXMLContext(context.Background(), path, query, object, headers)
+}
+
+func PutXMLContext(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
+
+	return DefaultClient.PutXMLContext(ctx, path, query, object, headers)
+}
+
+func PutYAML(path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
+
+	return DefaultClient.PutYAMLContext(context.Background(), path, query, object, headers)
+}
+
+func PutYAMLContext(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
+
+	return DefaultClient.PutYAMLContext(ctx, path, query, object, headers)
 }
 
 func Patch(path string, query url.Values, body io.Reader, headers map[string][]string) (*Response, error) {
 
-	return DefaultClient.Patch(path, query, body, headers)
+	return DefaultClient.PatchContext(context.Background(), path, query, body, headers)
+}
+
+func PatchContext(ctx context.Context, path string, query url.Values, body io.Reader, headers map[string][]string) (*Response, error) {
+
+	return DefaultClient.PatchContext(ctx, path, query, body, headers)
 }
 
 func PatchJSON(path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
 
-	return DefaultClient.PatchJSON(path, query, object, headers)
+	return DefaultClient.PatchJSONContext(context.Background(), path, query, object, headers)
+}
+
+func PatchJSONContext(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
+
+	return DefaultClient.PatchJSONContext(ctx, path, query, object, headers)
+}
+
+func PatchXML(path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
+
+	return DefaultClient.PatchXMLContext(context.Background(), path, query, object, headers)
+}
+
+func PatchXMLContext(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
+
+	return DefaultClient.PatchXMLContext(ctx, path, query, object, headers)
+}
+
+func PatchYAML(path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
+
+	return DefaultClient.PatchYAMLContext(context.Background(), path, query, object, headers)
+}
+
+func PatchYAMLContext(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
+
+	return DefaultClient.PatchYAMLContext(ctx, path, query, object, headers)
 }
 
 func Delete(path string, query url.Values, headers map[string][]string) (*Response, error) {
 
-	return DefaultClient.Delete(path, query, headers)
+	return DefaultClient.DeleteContext(context.Background(), path, query, headers)
+}
+
+func DeleteContext(ctx context.Context, path string, query url.Values, headers map[string][]string) (*Response, error) {
+
+	return DefaultClient.DeleteContext(ctx, path, query, headers)
 }
 
 func (client *HttpClient) Head(path string, query url.Values, headers map[string][]string) (*Response, error) {
 
-	return client.doSendRequest("HEAD", path, query, nil, headers)
+	return client.HeadContext(context.Background(), path, query, headers)
+}
+
+func (client *HttpClient) HeadContext(ctx context.Context, path string, query url.Values, headers map[string][]string) (*Response, error) {
+
+	return newResponse(client.c.Head(ctx, path, query, headers))
 }
 
 func (client *HttpClient) Options(path string, query url.Values, headers map[string][]string) (*Response, error) {
 
-	return client.doSendRequest("OPTIONS", path, query, nil, headers)
+	return client.OptionsContext(context.Background(), path, query, headers)
+}
+
+func (client *HttpClient) OptionsContext(ctx context.Context, path string, query url.Values, headers map[string][]string) (*Response, error) {
+
+	return newResponse(client.c.Options(ctx, path, query, headers))
 }
 
 func (client *HttpClient) Get(path string, query url.Values, headers map[string][]string) (*Response, error) {
 
-	return client.doSendRequest("GET", path, query, nil, headers)
+	return client.GetContext(context.Background(), path, query, headers)
+}
+
+func (client *HttpClient) GetContext(ctx context.Context, path string, query url.Values, headers map[string][]string) (*Response, error) {
+
+	return newResponse(client.c.Get(ctx, path, query, headers))
 }
 
 func (client *HttpClient) Post(path string, query url.Values, body io.Reader, headers map[string][]string) (*Response, error) {
 
-	return client.doSendRequest("POST", path, query, body, headers)
+	return client.PostContext(context.Background(), path, query, body, headers)
+}
+
+func (client *HttpClient) PostContext(ctx context.Context, path string, query url.Values, body io.Reader, headers map[string][]string) (*Response, error) {
+
+	return newResponse(client.c.Post(ctx, path, query, body, headers))
 }
 
 func (client *HttpClient) PostJSON(path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
 
-	body, err := makeJsonBody(object, headers)
-	if err != nil {
-		return nil, err
+	return client.PostJSONContext(context.Background(), path, query, object, headers)
+}
+
+func (client *HttpClient) PostJSONContext(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
+
+	if object == nil {
+		return client.PostContext(ctx, path, query, nil, headers)
 	}
-	return client.doSendRequest("POST", path, query, body, headers)
+	return newResponse(client.c.PostJSON(ctx, path, query, object, headers))
 }
 
-func (client *HttpClient) Put(path string, query url.Values, body io.Reader, headers map[string][]string) (*Response, error) {
+func (client *HttpClient) PostXML(path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
 
-	return client.doSendRequest("PUT", path, query, body, headers)
+	return client.PostXMLContext(context.Background(), path, query, object, headers)
 }
 
-func (client *HttpClient) PutJSON(path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
+func (client *HttpClient) PostXMLContext(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
 
-	body, err := makeJsonBody(object, headers)
-	if err != nil {
-		return nil, err
+	if object == nil {
+		return client.PostContext(ctx, path, query, nil, headers)
 	}
-	return client.doSendRequest("PUT", path, query, body, headers)
+	return newResponse(client.c.PostXML(ctx, path, query, object, headers))
 }
 
-func (client *HttpClient) Patch(path string, query url.Values, body io.Reader, headers map[string][]string) (*Response, error) {
+func (client *HttpClient) PostYAML(path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
 
-	return client.doSendRequest("PATCH", path, query, body, headers)
+	return client.PostYAMLContext(context.Background(), path, query, object, headers)
 }
 
-func (client *HttpClient) PatchJSON(path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
+func (client *HttpClient) PostYAMLContext(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
 
-	body, err := makeJsonBody(object, headers)
-	if err != nil {
-		return nil, err
+	if object == nil {
+		return client.PostContext(ctx, path, query, nil, headers)
 	}
-	return client.doSendRequest("PATCH", path, query, body, headers)
+	return newResponse(client.c.PostYAML(ctx, path, query, object, headers))
 }
 
-func (client *HttpClient) Delete(path string, query url.Values, headers map[string][]string) (*Response, error) {
+func (client *HttpClient) Put(path string, query url.Values, body io.Reader, headers map[string][]string) (*Response, error) {
 
-	return client.doSendRequest("DELETE", path, query, nil, headers)
+	return client.PutContext(context.Background(), path, query, body, headers)
 }
 
-func (client *HttpClient) doSendRequest(method string, path string, query url.Values, body io.Reader, headers map[string][]string) (*Response, error) {
+func (client *HttpClient) PutContext(ctx context.Context, path string, query url.Values, body io.Reader, headers map[string][]string) (*Response, error) {
 
-	resp := &Response{
-		body:       nil,
-		statuscode: -1,
-	}
+	return newResponse(client.c.Put(ctx, path, query, body, headers))
+}
 
-	ispayload := (method == "POST" || method == "PUT" || method == "PATCH")
-	if ispayload && body == nil {
-		body = bytes.NewReader([]byte{})
-	}
+func (client *HttpClient) PutJSON(path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
 
-	request, err := client.newRequest(method, path, query, body, headers)
-	if err != nil {
-		return nil, err
-	}
+	return client.PutJSONContext(context.Background(), path, query, object, headers)
+}
 
-	if ispayload && request.Header.Get("Content-Type") == "" {
-		request.Header.Set("Content-Type", "text/plain")
-	}
+func (client *HttpClient) PutJSONContext(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
 
-	response, err := client.c.Do(request)
-	if err != nil {
-		return nil, err
+	if object == nil {
+		return client.PutContext(ctx, path, query, nil, headers)
 	}
+	return newResponse(client.c.PutJSON(ctx, path, query, object, headers))
+}
+
+func (client *HttpClient) PutXML(path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
 
-	resp.rawurl = request.URL.String()
-	resp.body = response.Body
-	resp.header = response.Header
-	resp.statuscode = response.StatusCode
-	return resp, nil
+	return client.PutXMLContext(context.Background(), path, query, object, headers)
 }
 
-func (client *HttpClient) newRequest(method, path string, query url.Values, body io.Reader, headers map[string][]string) (*http.Request, error) {
+func (client *HttpClient) PutXMLContext(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
 
-	rawurl := &url.URL{
-		Path: path,
+	if object == nil {
+		return client.PutContext(ctx, path, query, nil, headers)
 	}
+	return newResponse(client.c.PutXML(ctx, path, query, object, headers))
+}
 
-	if len(query) > 0 {
-		rawurl.RawQuery = query.Encode()
-	}
+func (client *HttpClient) PutYAML(path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
 
-	request, err := http.NewRequest(method, rawurl.String(), body)
-	if err != nil {
-		return nil, err
-	}
+	return client.PutYAMLContext(context.Background(), path, query, object, headers)
+}
 
-	for key, value := range client.headers {
-		request.Header.Set(key, value)
-	}
+func (client *HttpClient) PutYAMLContext(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
 
-	if headers != nil {
-		for key, value := range headers {
-			request.Header[key] = value
-		}
+	if object == nil {
+		return client.PutContext(ctx, path, query, nil, headers)
 	}
+	return newResponse(client.c.PutYAML(ctx, path, query, object, headers))
+}
 
-	if len(client.cookies) != 0 {
-		for _, cookie := range client.cookies {
-			request.AddCookie(cookie)
-		}
-	}
+func (client *HttpClient) Patch(path string, query url.Values, body io.Reader, headers map[string][]string) (*Response, error) {
+
+	return client.PatchContext(context.Background(), path, query, body, headers)
+}
+
+func (client *HttpClient) PatchContext(ctx context.Context, path string, query url.Values, body io.Reader, headers map[string][]string) (*Response, error) {
+
+	return newResponse(client.c.Patch(ctx, path, query, body, headers))
+}
+
+func (client *HttpClient) PatchJSON(path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
+
+	return client.PatchJSONContext(context.Background(), path, query, object, headers)
+}
+
+func (client *HttpClient) PatchJSONContext(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
 
-	if client.auth.username != "" && client.auth.password != "" {
-		request.SetBasicAuth(client.auth.username, client.auth.password)
+	if object == nil {
+		return client.PatchContext(ctx, path, query, nil, headers)
 	}
-	return request, nil
-}
-
-func makeJsonBody(object interface{}, headers map[string][]string) (io.Reader, error) {
-
-	var body io.Reader
-	if object != nil {
-		var err error
-		body, err = encodeJsonData(object)
-		if err != nil {
-			return nil, err
-		}
-		if headers == nil {
-			headers = make(map[string][]string)
-		}
-		headers["Content-Type"] = []string{"application/json;charset=utf-8"}
+	return newResponse(client.c.PatchJSON(ctx, path, query, object, headers))
+}
+
+func (client *HttpClient) PatchXML(path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
+
+	return client.PatchXMLContext(context.Background(), path, query, object, headers)
+}
+
+func (client *HttpClient) PatchXMLContext(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
+
+	if object == nil {
+		return client.PatchContext(ctx, path, query, nil, headers)
 	}
-	return body, nil
+	return newResponse(client.c.PatchXML(ctx, path, query, object, headers))
+}
+
+func (client *HttpClient) PatchYAML(path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
+
+	return client.PatchYAMLContext(context.Background(), path, query, object, headers)
 }
 
-func encodeJsonData(object interface{}) (*bytes.Buffer, error) {
+func (client *HttpClient) PatchYAMLContext(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*Response, error) {
 
-	buffer := bytes.NewBuffer(nil)
-	if object != nil {
-		if err := json.NewEncoder(buffer).Encode(object); err != nil {
-			return nil, err
-		}
+	if object == nil {
+		return client.PatchContext(ctx, path, query, nil, headers)
 	}
-	return buffer, nil
+	return newResponse(client.c.PatchYAML(ctx, path, query, object, headers))
+}
+
+func (client *HttpClient) Delete(path string, query url.Values, headers map[string][]string) (*Response, error) {
+
+	return client.DeleteContext(context.Background(), path, query, headers)
+}
+
+func (client *HttpClient) DeleteContext(ctx context.Context, path string, query url.Values, headers map[string][]string) (*Response, error) {
+
+	return newResponse(client.c.Delete(ctx, path, query, headers))
 }
@@ -0,0 +1,142 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPostJSONContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+		w.Write([]byte("{\"query\":\"" + r.URL.Query().Get("name") + "\"}"))
+	}))
+	defer server.Close()
+
+	resp, err := NewClient().PostJSON(server.URL+"/nodes", map[string][]string{"name": {"humpback"}}, map[string]string{"ip": "127.0.0.1"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+	value := map[string]string{}
+	if err := resp.JSON(&value); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode() != http.StatusOK || value["query"] != "humpback" || resp.Header("Content-Type") != "application/json;charset=utf-8" {
+		t.Errorf("unexpected response %d %v %s", resp.StatusCode(), value, resp.Header("Content-Type"))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := GetContext(ctx, server.URL+"/slow", nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestPostYAML(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+		io.Copy(w, r.Body)
+	}))
+	defer server.Close()
+
+	type service struct {
+		Image    string   `yaml:"image" xml:"image"`
+		Ports    []string `yaml:"ports" xml:"port"`
+		Replicas int      `yaml:"replicas" xml:"replicas"`
+	}
+
+	compose := service{Image: "humpback/agent", Ports: []string{"8500:8500"}, Replicas: 2}
+	resp, err := PostYAML(server.URL, nil, compose, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+	value := service{}
+	if err := resp.YAML(&value); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Header("Content-Type") != "application/yaml;charset=utf-8" || value.Image != compose.Image || len(value.Ports) != 1 || value.Replicas != 2 {
+		t.Errorf("unexpected yaml response %s %+v", resp.Header("Content-Type"), value)
+	}
+
+	resp, err = PutXML(server.URL, nil, compose, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+	value = service{}
+	if err := resp.XML(&value); err != nil {
+		t.Fatal(err)
+	}
+	if value.Image != compose.Image || value.Replicas != 2 {
+		t.Errorf("unexpected xml response %+v", value)
+	}
+}
+
+func TestMaxBodySize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush()
+		for i := 0; i < 64; i++ {
+			w.Write([]byte("0123456789abcdef"))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient().SetMaxBodySize(512)
+	resp, err := client.Get(server.URL, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := resp.Bytes(); !errors.Is(err, ErrBodyTooLarge) {
+		t.Errorf("unexpected error %v", err)
+	}
+	resp.Close()
+
+	resp, err = client.GetContext(WithMaxBodySize(context.Background(), ResponseBodyAllSize), server.URL, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := resp.Bytes(); err != nil || len(data) != 1024 {
+		t.Errorf("unexpected body %d %v", len(data), err)
+	}
+	resp.Close()
+
+	resp, err = client.Get(server.URL, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := resp.LimitedBytes(10); err != nil || string(data) != "0123456789" {
+		t.Errorf("unexpected prefix %q %v", data, err)
+	}
+	resp.Close()
+
+	resp, err = client.Get(server.URL, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := resp.LimitedBytes(4096); err != nil || len(data) != 512 {
+		t.Errorf("unexpected limited body %d %v", len(data), err)
+	}
+	resp.Close()
+}
+
+func TestPostJSONEncodeError(t *testing.T) {
+	object := map[string]interface{}{"f": func() {}}
+	if _, err := PostJSON("http://127.0.0.1:1/nodes", nil, object, nil); err == nil {
+		t.Errorf("expected json encode error")
+	}
+	if _, err := PutXML("http://127.0.0.1:1/nodes", nil, object, nil); err == nil {
+		t.Errorf("expected xml encode error")
+	}
+	if _, err := PostYAML("http://127.0.0.1:1/nodes", nil, func() {}, nil); err == nil {
+		t.Errorf("expected yaml encode error")
+	}
+}
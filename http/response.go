@@ -1,80 +1,94 @@
 package http
 
+import "github.com/humpback/gounits/httpx"
+
 import (
-	"encoding/json"
-	"io"
-	"io/ioutil"
+	"context"
 	"net/http"
 )
 
-const ResponseBodyAllSize int64 = 0
+const ResponseBodyAllSize int64 = httpx.ResponseBodyAllSize
+
+var ErrBodyTooLarge = httpx.ErrBodyTooLarge
 
 type Response struct {
-	rawurl     string
-	body       io.ReadCloser
-	header     http.Header
-	statuscode int
+	resp *httpx.HttpResponse
+}
+
+func newResponse(resp *httpx.HttpResponse, err error) (*Response, error) {
+
+	if err != nil {
+		return nil, err
+	}
+	return &Response{resp: resp}, nil
+}
+
+func WithMaxBodySize(ctx context.Context, size int64) context.Context {
+
+	return httpx.WithMaxBodySize(ctx, size)
 }
 
 func (resp *Response) Bytes() ([]byte, error) {
 
-	return ioutil.ReadAll(resp.body)
+	return resp.resp.Bytes()
+}
+
+func (resp *Response) LimitedBytes(n int64) ([]byte, error) {
+
+	return resp.resp.LimitedBytes(n)
 }
 
 func (resp *Response) String() string {
 
-	buf, err := resp.Bytes()
-	if err != nil {
-		return ""
-	}
-	return string(buf)
+	return resp.resp.String()
 }
 
 func (resp *Response) JSON(object interface{}) error {
 
-	buf, err := resp.Bytes()
-	if err != nil {
-		return err
-	}
-	return json.Unmarshal(buf, object)
+	return resp.resp.JSON(object)
+}
+
+func (resp *Response) XML(object interface{}) error {
+
+	return resp.resp.XML(object)
+}
+
+func (resp *Response) YAML(object interface{}) error {
+
+	return resp.resp.YAML(object)
 }
 
 func (resp *Response) JSONMapper(data interface{}) error {
 
-	dec := json.NewDecoder(resp.body)
-	for {
-		if err := dec.Decode(data); err != nil {
-			if err == io.EOF {
-				break
-			}
-			return err
-		}
-	}
-	return nil
+	return resp.resp.JSONMapper(data)
+}
+
+func (resp *Response) XMLMapper(data interface{}) error {
+
+	return resp.resp.XMLMapper(data)
 }
 
 func (resp *Response) RawURL() string {
 
-	return resp.rawurl
+	return resp.resp.RawURL()
 }
 
 func (resp *Response) Header(key string) string {
 
-	return resp.header.Get(key)
+	return resp.resp.Header(key)
 }
 
 func (resp *Response) Headers() http.Header {
 
-	return resp.header
+	return resp.resp.Headers()
 }
 
 func (resp *Response) StatusCode() int {
 
-	return resp.statuscode
+	return resp.resp.StatusCode()
 }
 
 func (resp *Response) Close() error {
 
-	io.Copy(ioutil.Discard, resp.body)
-	return resp.body.Close()
+	return resp.resp.Close()
 }
@@ -0,0 +1,99 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+var ErrBodyTooLarge = errors.New("client response body too large.")
+
+type BodyTooLargeError struct {
+	Limit int64
+}
+
+func (e *BodyTooLargeError) Error() string {
+
+	return fmt.Sprintf("client response body exceeds %d bytes", e.Limit)
+}
+
+func (e *BodyTooLargeError) Is(target error) bool {
+
+	return target == ErrBodyTooLarge
+}
+
+func (client *HttpClient) SetMaxBodySize(size int64) *HttpClient {
+
+	return client.update(func(config *clientConfig) {
+		config.maxbodysize = size
+	})
+}
+
+func WithMaxBodySize(ctx context.Context, size int64) context.Context {
+
+	return context.WithValue(ctx, maxBodySizeKey, size)
+}
+
+func (config *clientConfig) maxBodySize(ctx context.Context) int64 {
+
+	if size, ok := ctx.Value(maxBodySizeKey).(int64); ok {
+		return size
+	}
+	return config.maxbodysize
+}
+
+type limitedReader struct {
+	reader io.Reader
+	limit  int64
+	remain int64
+}
+
+func newLimitedReader(reader io.Reader, limit int64) io.Reader {
+
+	if limit <= ResponseBodyAllSize {
+		return reader
+	}
+
+	return &limitedReader{
+		reader: reader,
+		limit:  limit,
+		remain: limit,
+	}
+}
+
+func (limited *limitedReader) Read(p []byte) (int, error) {
+
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	if limited.remain <= 0 {
+		n, err := limited.reader.Read(p[:1])
+		if n > 0 {
+			return 0, &BodyTooLargeError{Limit: limited.limit}
+		}
+		return 0, err
+	}
+
+	if int64(len(p)) > limited.remain {
+		p = p[:limited.remain]
+	}
+
+	n, err := limited.reader.Read(p)
+	limited.remain -= int64(n)
+	return n, err
+}
+
+func readBody(reader io.Reader, length int64, limit int64) ([]byte, error) {
+
+	if limit <= ResponseBodyAllSize {
+		return ioutil.ReadAll(reader)
+	}
+
+	if length > limit {
+		return nil, &BodyTooLargeError{Limit: limit}
+	}
+	return ioutil.ReadAll(newLimitedReader(reader, limit))
+}
@@ -0,0 +1,242 @@
+package httpx
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+func (state BreakerState) String() string {
+
+	switch state {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+var ErrBreakerOpen = errors.New("client circuit breaker open.")
+
+type BreakerOpenError struct {
+	Host       string
+	State      BreakerState
+	RetryAfter time.Duration
+}
+
+func (e *BreakerOpenError) Error() string {
+
+	return fmt.Sprintf("client circuit breaker %s for %s, retry after %s", e.State, e.Host, e.RetryAfter)
+}
+
+func (e *BreakerOpenError) Is(target error) bool {
+
+	return target == ErrBreakerOpen
+}
+
+type BreakerOptions struct {
+	Window           time.Duration
+	MinRequests      int
+	FailureRate      float64
+	CoolDown         time.Duration
+	HalfOpenRequests int
+	OnStateChange    func(host string, from BreakerState, to BreakerState)
+}
+
+var DefaultBreakerOptions = &BreakerOptions{
+	Window:           60 * time.Second,
+	MinRequests:      10,
+	FailureRate:      0.5,
+	CoolDown:         30 * time.Second,
+	HalfOpenRequests: 1,
+}
+
+type hostBreaker struct {
+	state       BreakerState
+	windowStart time.Time
+	openedAt    time.Time
+	requests    int
+	failures    int
+	probes      int
+}
+
+type circuitBreakers struct {
+	sync.Mutex
+	options *BreakerOptions
+	hosts   map[string]*hostBreaker
+}
+
+func (client *HttpClient) SetCircuitBreaker(options *BreakerOptions) *HttpClient {
+
+	var breakers *circuitBreakers
+	if options != nil {
+		breakers = &circuitBreakers{
+			options: options.withDefaults(),
+			hosts:   make(map[string]*hostBreaker),
+		}
+	}
+
+	return client.update(func(config *clientConfig) {
+		config.breakers = breakers
+	})
+}
+
+func (options *BreakerOptions) withDefaults() *BreakerOptions {
+
+	filled := *options
+	if filled.Window <= 0 {
+		filled.Window = DefaultBreakerOptions.Window
+	}
+
+	if filled.MinRequests <= 0 {
+		filled.MinRequests = DefaultBreakerOptions.MinRequests
+	}
+
+	if filled.FailureRate <= 0 {
+		filled.FailureRate = DefaultBreakerOptions.FailureRate
+	}
+
+	if filled.CoolDown <= 0 {
+		filled.CoolDown = DefaultBreakerOptions.CoolDown
+	}
+
+	if filled.HalfOpenRequests <= 0 {
+		filled.HalfOpenRequests = DefaultBreakerOptions.HalfOpenRequests
+	}
+	return &filled
+}
+
+func (client *HttpClient) BreakerState(host string) BreakerState {
+
+	breakers := client.load().breakers
+	if breakers == nil {
+		return BreakerClosed
+	}
+
+	breakers.Lock()
+	defer breakers.Unlock()
+	if breaker, ret := breakers.hosts[host]; ret {
+		return breaker.state
+	}
+	return BreakerClosed
+}
+
+func (breakers *circuitBreakers) allow(host string) error {
+
+	breakers.Lock()
+	breaker, ret := breakers.hosts[host]
+	if !ret {
+		breaker = &hostBreaker{
+			state:       BreakerClosed,
+			windowStart: time.Now(),
+		}
+		breakers.hosts[host] = breaker
+	}
+
+	from := breaker.state
+	switch breaker.state {
+	case BreakerOpen:
+		elapsed := time.Since(breaker.openedAt)
+		if elapsed < breakers.options.CoolDown {
+			breakers.Unlock()
+			return &BreakerOpenError{Host: host, State: BreakerOpen, RetryAfter: breakers.options.CoolDown - elapsed}
+		}
+		breaker.state = BreakerHalfOpen
+		breaker.probes = 1
+	case BreakerHalfOpen:
+		limit := breakers.options.HalfOpenRequests
+		if limit <= 0 {
+			limit = 1
+		}
+		if breaker.probes >= limit {
+			breakers.Unlock()
+			return &BreakerOpenError{Host: host, State: BreakerHalfOpen}
+		}
+		breaker.probes++
+	}
+	to := breaker.state
+	breakers.Unlock()
+	breakers.notify(host, from, to)
+	return nil
+}
+
+func (breakers *circuitBreakers) record(host string, failed bool) {
+
+	breakers.Lock()
+	breaker, ret := breakers.hosts[host]
+	if !ret {
+		breakers.Unlock()
+		return
+	}
+
+	now := time.Now()
+	from := breaker.state
+	switch breaker.state {
+	case BreakerHalfOpen:
+		breaker.probes--
+		if failed {
+			breaker.state = BreakerOpen
+			breaker.openedAt = now
+		} else {
+			breaker.state = BreakerClosed
+			breaker.windowStart = now
+			breaker.requests = 0
+			breaker.failures = 0
+		}
+	case BreakerClosed:
+		if breakers.options.Window > 0 && now.Sub(breaker.windowStart) > breakers.options.Window {
+			breaker.windowStart = now
+			breaker.requests = 0
+			breaker.failures = 0
+		}
+		breaker.requests++
+		if failed {
+			breaker.failures++
+		}
+		if breaker.requests >= breakers.options.MinRequests &&
+			float64(breaker.failures)/float64(breaker.requests) >= breakers.options.FailureRate {
+			breaker.state = BreakerOpen
+			breaker.openedAt = now
+		}
+	}
+	to := breaker.state
+	breakers.Unlock()
+	breakers.notify(host, from, to)
+}
+
+func (breakers *circuitBreakers) release(host string) {
+
+	breakers.Lock()
+	if breaker, ret := breakers.hosts[host]; ret && breaker.state == BreakerHalfOpen {
+		breaker.probes--
+	}
+	breakers.Unlock()
+}
+
+func (breakers *circuitBreakers) notify(host string, from BreakerState, to BreakerState) {
+
+	if from != to && breakers.options.OnStateChange != nil {
+		breakers.options.OnStateChange(host, from, to)
+	}
+}
+
+func breakerFailed(response *http.Response, err error) bool {
+
+	if err != nil {
+		return true
+	}
+	return response.StatusCode >= http.StatusInternalServerError
+}
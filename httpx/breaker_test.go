@@ -0,0 +1,148 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerDefaults(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	changes := 0
+	options := &BreakerOptions{
+		OnStateChange: func(host string, from BreakerState, to BreakerState) {
+			changes++
+		},
+	}
+
+	client := NewClient().SetCircuitBreaker(options)
+	filled := client.load().breakers.options
+	if filled.Window != DefaultBreakerOptions.Window || filled.MinRequests != DefaultBreakerOptions.MinRequests ||
+		filled.FailureRate != DefaultBreakerOptions.FailureRate || filled.CoolDown != DefaultBreakerOptions.CoolDown ||
+		filled.HalfOpenRequests != DefaultBreakerOptions.HalfOpenRequests || filled.OnStateChange == nil {
+		t.Errorf("unexpected breaker options %+v", filled)
+	}
+	if options.FailureRate != 0 {
+		t.Error("caller options modified")
+	}
+
+	for i := 0; i < 20; i++ {
+		resp, err := client.Get(context.Background(), server.URL, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Close()
+	}
+
+	rawurl, _ := url.Parse(server.URL)
+	if state := client.BreakerState(rawurl.Host); state != BreakerClosed || changes != 0 {
+		t.Errorf("unexpected breaker state %s after %d changes", state, changes)
+	}
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	var failing int32 = 1
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-release
+		}
+		if atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var (
+		mutex   sync.Mutex
+		changes []string
+	)
+	client := NewClient().SetCircuitBreaker(&BreakerOptions{
+		MinRequests: 2,
+		CoolDown:    50 * time.Millisecond,
+		OnStateChange: func(host string, from BreakerState, to BreakerState) {
+			mutex.Lock()
+			changes = append(changes, from.String()+">"+to.String())
+			mutex.Unlock()
+		},
+	})
+	rawurl, _ := url.Parse(server.URL)
+	get := func(path string) error {
+		resp, err := client.Get(context.Background(), server.URL+path, nil, nil)
+		if err == nil {
+			resp.Close()
+		}
+		return err
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := get("/"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if state := client.BreakerState(rawurl.Host); state != BreakerOpen {
+		t.Fatalf("unexpected breaker state %s", state)
+	}
+
+	var openErr *BreakerOpenError
+	err := get("/")
+	if !errors.Is(err, ErrBreakerOpen) || !errors.As(err, &openErr) {
+		t.Fatalf("unexpected open error %v", err)
+	}
+	if openErr.Host != rawurl.Host || openErr.State != BreakerOpen || openErr.RetryAfter <= 0 {
+		t.Errorf("unexpected open error %+v", openErr)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if err := get("/"); err != nil {
+		t.Fatal(err)
+	}
+	if state := client.BreakerState(rawurl.Host); state != BreakerOpen {
+		t.Fatalf("unexpected breaker state after failed probe %s", state)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	atomic.StoreInt32(&failing, 0)
+	done := make(chan error)
+	go func() {
+		done <- get("/slow")
+	}()
+	for client.BreakerState(rawurl.Host) != BreakerHalfOpen {
+		time.Sleep(time.Millisecond)
+	}
+	if err := get("/"); !errors.As(err, &openErr) || openErr.State != BreakerHalfOpen {
+		t.Errorf("unexpected half-open error %v", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if state := client.BreakerState(rawurl.Host); state != BreakerClosed {
+		t.Errorf("unexpected breaker state after probe %s", state)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	expected := []string{"closed>open", "open>half-open", "half-open>open", "open>half-open", "half-open>closed"}
+	if len(changes) != len(expected) {
+		t.Fatalf("unexpected state changes %v", changes)
+	}
+	for i := range expected {
+		if changes[i] != expected[i] {
+			t.Errorf("unexpected state changes %v", changes)
+			break
+		}
+	}
+}
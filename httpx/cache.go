@@ -0,0 +1,392 @@
+package httpx
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const DefaultCacheMaxEntrySize int64 = 1 << 20
+
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte)
+	Delete(key string)
+}
+
+type cacheEntry struct {
+	StatusCode int               `json:"statuscode"`
+	Status     string            `json:"status"`
+	Header     http.Header       `json:"header"`
+	Body       []byte            `json:"body"`
+	Vary       map[string]string `json:"vary"`
+	StoredAt   time.Time         `json:"storedat"`
+}
+
+type memoryItem struct {
+	key   string
+	value []byte
+}
+
+type MemoryCache struct {
+	sync.Mutex
+	capacity int
+	items    *list.List
+	keys     map[string]*list.Element
+}
+
+func NewMemoryCache(capacity int) *MemoryCache {
+
+	if capacity <= 0 {
+		capacity = 1024
+	}
+
+	return &MemoryCache{
+		capacity: capacity,
+		items:    list.New(),
+		keys:     make(map[string]*list.Element),
+	}
+}
+
+func (cache *MemoryCache) Get(key string) ([]byte, bool) {
+
+	cache.Lock()
+	defer cache.Unlock()
+	if element, ret := cache.keys[key]; ret {
+		cache.items.MoveToFront(element)
+		return element.Value.(*memoryItem).value, true
+	}
+	return nil, false
+}
+
+func (cache *MemoryCache) Set(key string, value []byte) {
+
+	cache.Lock()
+	defer cache.Unlock()
+	if element, ret := cache.keys[key]; ret {
+		element.Value.(*memoryItem).value = value
+		cache.items.MoveToFront(element)
+		return
+	}
+
+	cache.keys[key] = cache.items.PushFront(&memoryItem{key: key, value: value})
+	for cache.items.Len() > cache.capacity {
+		element := cache.items.Back()
+		cache.items.Remove(element)
+		delete(cache.keys, element.Value.(*memoryItem).key)
+	}
+}
+
+func (cache *MemoryCache) Delete(key string) {
+
+	cache.Lock()
+	defer cache.Unlock()
+	if element, ret := cache.keys[key]; ret {
+		cache.items.Remove(element)
+		delete(cache.keys, key)
+	}
+}
+
+type DiskCache struct {
+	dir string
+}
+
+func NewDiskCache(dir string) (*DiskCache, error) {
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+func (cache *DiskCache) Get(key string) ([]byte, bool) {
+
+	value, err := ioutil.ReadFile(cache.filePath(key))
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+func (cache *DiskCache) Set(key string, value []byte) {
+
+	fpath := cache.filePath(key)
+	fd, err := ioutil.TempFile(cache.dir, ".cache")
+	if err != nil {
+		return
+	}
+
+	if _, err := fd.Write(value); err != nil {
+		fd.Close()
+		os.Remove(fd.Name())
+		return
+	}
+
+	fd.Close()
+	if err := os.Rename(fd.Name(), fpath); err != nil {
+		os.Remove(fd.Name())
+	}
+}
+
+func (cache *DiskCache) Delete(key string) {
+
+	os.Remove(cache.filePath(key))
+}
+
+func (cache *DiskCache) filePath(key string) string {
+
+	return filepath.Join(cache.dir, fmt.Sprintf("%x", sha256.Sum256([]byte(key))))
+}
+
+func (client *HttpClient) SetCache(cache Cache) *HttpClient {
+
+	return client.update(func(config *clientConfig) {
+		config.cache = cache
+	})
+}
+
+func (client *HttpClient) doCache(ctx context.Context, request *http.Request, state *requestState) (*http.Response, error) {
+
+	cache := state.config.cache
+	if cache == nil || request.Method != http.MethodGet || request.Header.Get("Range") != "" || state.config.credentialed(request) {
+		return client.doFailover(ctx, request, state)
+	}
+
+	key := request.Method + " " + request.URL.String()
+	requestControl := parseCacheControl(request.Header.Get("Cache-Control"))
+	if _, ret := requestControl["no-store"]; ret {
+		return client.doFailover(ctx, request, state)
+	}
+
+	entry := loadCacheEntry(cache, key, request)
+	if entry != nil {
+		_, nocache := requestControl["no-cache"]
+		if !nocache && entry.fresh() {
+			state.cachehit = true
+			return entry.response(request), nil
+		}
+
+		if etag := entry.Header.Get("ETag"); etag != "" {
+			request.Header.Set("If-None-Match", etag)
+		}
+
+		if modified := entry.Header.Get("Last-Modified"); modified != "" {
+			request.Header.Set("If-Modified-Since", modified)
+		}
+	}
+
+	response, err := client.doFailover(ctx, request, state)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry != nil && response.StatusCode == http.StatusNotModified {
+		response.Body.Close()
+		for key, value := range response.Header {
+			entry.Header[key] = value
+		}
+		entry.StoredAt = time.Now()
+		storeCacheEntry(cache, key, entry)
+		state.cachehit = true
+		return entry.response(request), nil
+	}
+
+	if !cacheable(response) {
+		return response, nil
+	}
+
+	limit := state.config.maxBodySize(ctx)
+	if response.ContentLength < 0 && limit <= ResponseBodyAllSize {
+		return response, nil
+	}
+
+	if limit <= ResponseBodyAllSize || limit > DefaultCacheMaxEntrySize {
+		limit = DefaultCacheMaxEntrySize
+	}
+
+	if response.ContentLength > limit {
+		return response, nil
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(response.Body, limit+1))
+	if err != nil {
+		response.Body.Close()
+		return nil, err
+	}
+
+	if int64(len(body)) > limit {
+		response.Body = &struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), response.Body), response.Body}
+		return response, nil
+	}
+
+	response.Body.Close()
+
+	response.Body = ioutil.NopCloser(bytes.NewReader(body))
+	storeCacheEntry(cache, key, &cacheEntry{
+		StatusCode: response.StatusCode,
+		Status:     response.Status,
+		Header:     response.Header.Clone(),
+		Body:       body,
+		Vary:       varyValues(response.Header, request.Header),
+		StoredAt:   time.Now(),
+	})
+	return response, nil
+}
+
+func (config *clientConfig) credentialed(request *http.Request) bool {
+
+	if config.tokens != nil {
+		return true
+	}
+
+	if config.jar != nil && len(config.jar.Cookies(request.URL)) > 0 {
+		return true
+	}
+	return request.Header.Get("Authorization") != "" || request.Header.Get("Cookie") != ""
+}
+
+func loadCacheEntry(cache Cache, key string, request *http.Request) *cacheEntry {
+
+	value, ret := cache.Get(key)
+	if !ret {
+		return nil
+	}
+
+	entry := &cacheEntry{}
+	if err := json.Unmarshal(value, entry); err != nil {
+		cache.Delete(key)
+		return nil
+	}
+
+	for name, value := range entry.Vary {
+		if request.Header.Get(name) != value {
+			return nil
+		}
+	}
+	return entry
+}
+
+func storeCacheEntry(cache Cache, key string, entry *cacheEntry) {
+
+	if value, err := json.Marshal(entry); err == nil {
+		cache.Set(key, value)
+	}
+}
+
+func (entry *cacheEntry) fresh() bool {
+
+	control := parseCacheControl(entry.Header.Get("Cache-Control"))
+	if _, ret := control["no-cache"]; ret {
+		return false
+	}
+
+	lifetime := time.Duration(0)
+	if maxAge, ret := control["max-age"]; ret {
+		if seconds, err := strconv.Atoi(maxAge); err == nil {
+			lifetime = time.Duration(seconds) * time.Second
+		}
+	} else if expires := entry.Header.Get("Expires"); expires != "" {
+		expiresAt, err := http.ParseTime(expires)
+		if err != nil {
+			return false
+		}
+		date, err := http.ParseTime(entry.Header.Get("Date"))
+		if err != nil {
+			date = entry.StoredAt
+		}
+		lifetime = expiresAt.Sub(date)
+	}
+	return time.Since(entry.StoredAt) < lifetime
+}
+
+func (entry *cacheEntry) response(request *http.Request) *http.Response {
+
+	return &http.Response{
+		Status:        entry.Status,
+		StatusCode:    entry.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        entry.Header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(entry.Body)),
+		ContentLength: int64(len(entry.Body)),
+		Request:       request,
+	}
+}
+
+func cacheable(response *http.Response) bool {
+
+	if response.StatusCode != http.StatusOK {
+		return false
+	}
+
+	if response.Header.Get("Vary") == "*" {
+		return false
+	}
+
+	if mediatype, _, _ := mime.ParseMediaType(response.Header.Get("Content-Type")); mediatype == "text/event-stream" {
+		return false
+	}
+
+	control := parseCacheControl(response.Header.Get("Cache-Control"))
+	if _, ret := control["no-store"]; ret {
+		return false
+	}
+
+	if _, ret := control["max-age"]; ret {
+		return true
+	}
+	return response.Header.Get("Expires") != "" || response.Header.Get("ETag") != "" || response.Header.Get("Last-Modified") != ""
+}
+
+func varyValues(responseHeader http.Header, requestHeader http.Header) map[string]string {
+
+	values := make(map[string]string)
+	for _, vary := range responseHeader.Values("Vary") {
+		for _, name := range strings.Split(vary, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				values[http.CanonicalHeaderKey(name)] = requestHeader.Get(name)
+			}
+		}
+	}
+	return values
+}
+
+func parseCacheControl(value string) map[string]string {
+
+	control := make(map[string]string)
+	for _, directive := range strings.Split(value, ",") {
+		directive = strings.TrimSpace(directive)
+		if directive == "" {
+			continue
+		}
+
+		name, arg := directive, ""
+		if index := strings.Index(directive, "="); index >= 0 {
+			name, arg = directive[:index], strings.Trim(directive[index+1:], "\" ")
+		}
+		control[strings.ToLower(strings.TrimSpace(name))] = arg
+	}
+	return control
+}
@@ -0,0 +1,203 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func getCached(t *testing.T, client *HttpClient, rawurl string, headers map[string][]string) (string, bool) {
+	resp, err := client.Get(context.Background(), rawurl, nil, headers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+	return resp.String(), resp.CacheHit()
+}
+
+func TestCacheFreshness(t *testing.T) {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		switch r.URL.Path {
+		case "/fresh":
+			w.Header().Set("Cache-Control", "max-age=60")
+		case "/stale":
+			w.Header().Set("Cache-Control", "max-age=0")
+		case "/nostore":
+			w.Header().Set("Cache-Control", "no-store, max-age=60")
+		}
+		w.Write([]byte("humpback"))
+	}))
+	defer server.Close()
+
+	client := NewClient().SetCache(NewMemoryCache(0))
+	for _, test := range []struct {
+		path     string
+		requests int32
+	}{
+		{"/fresh", 1},
+		{"/stale", 2},
+		{"/nostore", 2},
+	} {
+		atomic.StoreInt32(&count, 0)
+		for i := 0; i < 2; i++ {
+			body, hit := getCached(t, client, server.URL+test.path, nil)
+			if body != "humpback" || hit != (i == 1 && test.requests == 1) {
+				t.Errorf("%s unexpected response %q hit %v", test.path, body, hit)
+			}
+		}
+		if count := atomic.LoadInt32(&count); count != test.requests {
+			t.Errorf("%s unexpected request count %d", test.path, count)
+		}
+	}
+}
+
+func TestCacheRevalidate(t *testing.T) {
+	var count, revalidated int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&revalidated, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte("humpback"))
+	}))
+	defer server.Close()
+
+	client := NewClient().SetCache(NewMemoryCache(0))
+	if body, hit := getCached(t, client, server.URL, nil); body != "humpback" || hit {
+		t.Errorf("unexpected response %q hit %v", body, hit)
+	}
+	if body, hit := getCached(t, client, server.URL, nil); body != "humpback" || !hit {
+		t.Errorf("unexpected revalidated response %q hit %v", body, hit)
+	}
+	if count, revalidated := atomic.LoadInt32(&count), atomic.LoadInt32(&revalidated); count != 2 || revalidated != 1 {
+		t.Errorf("unexpected requests %d revalidated %d", count, revalidated)
+	}
+}
+
+func TestCacheVary(t *testing.T) {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Vary", "Accept-Language")
+		w.Write([]byte(r.Header.Get("Accept-Language")))
+	}))
+	defer server.Close()
+
+	client := NewClient().SetCache(NewMemoryCache(0))
+	english := map[string][]string{"Accept-Language": {"en"}}
+	chinese := map[string][]string{"Accept-Language": {"zh"}}
+	for i, test := range []struct {
+		headers map[string][]string
+		body    string
+		hit     bool
+	}{
+		{english, "en", false},
+		{english, "en", true},
+		{chinese, "zh", false},
+		{chinese, "zh", true},
+	} {
+		if body, hit := getCached(t, client, server.URL, test.headers); body != test.body || hit != test.hit {
+			t.Errorf("%d unexpected response %q hit %v", i, body, hit)
+		}
+	}
+	if atomic.LoadInt32(&count) != 2 {
+		t.Errorf("unexpected request count %d", count)
+	}
+}
+
+func TestCacheSkipsUnboundedBodies(t *testing.T) {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		switch r.URL.Path {
+		case "/large":
+			w.Write([]byte(strings.Repeat("humpback", int(DefaultCacheMaxEntrySize/8)+1)))
+			return
+		case "/events":
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("ETag", `"v1"`)
+			w.Write([]byte("data: humpback\n\n"))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+			return
+		}
+		w.(http.Flusher).Flush()
+		w.Write([]byte("humpback"))
+	}))
+	defer server.Close()
+
+	client := NewClient().SetCache(NewMemoryCache(0))
+	for _, path := range []string{"/large", "/large", "/chunked", "/chunked"} {
+		if _, hit := getCached(t, client, server.URL+path, nil); hit {
+			t.Errorf("%s unexpected cache hit", path)
+		}
+	}
+	if atomic.LoadInt32(&count) != 4 {
+		t.Errorf("unexpected request count %d", count)
+	}
+
+	bounded := client.Clone().SetMaxBodySize(1024)
+	for i, path := range []string{"/chunked", "/chunked"} {
+		if body, hit := getCached(t, bounded, server.URL+path, nil); body != "humpback" || hit != (i == 1) {
+			t.Errorf("%d unexpected bounded response %q hit %v", i, body, hit)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := bounded.Get(ctx, server.URL+"/events", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Err() != nil {
+		t.Error("event stream buffered by cache")
+	}
+	cancel()
+	resp.Close()
+}
+
+func TestCacheSkipsCredentials(t *testing.T) {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer server.Close()
+
+	client := NewClient().SetCache(NewMemoryCache(0))
+	alice := client.With(func(client *HttpClient) {
+		client.SetTokenSource(StaticTokenSource("alice"))
+	})
+	bob := client.With(func(client *HttpClient) {
+		client.SetTokenSource(StaticTokenSource("bob"))
+	})
+	basic := client.With(func(client *HttpClient) {
+		client.SetBasicAuth("carol", "secret")
+	})
+
+	for _, c := range []*HttpClient{alice, bob, basic, alice} {
+		resp, err := c.Get(context.Background(), server.URL, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.CacheHit() {
+			t.Errorf("unexpected cache hit for %q", resp.String())
+		}
+		resp.Close()
+	}
+	if atomic.LoadInt32(&count) != 4 {
+		t.Errorf("unexpected request count %d", count)
+	}
+}
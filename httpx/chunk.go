@@ -0,0 +1,223 @@
+package httpx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+)
+
+const (
+	DefaultChunkSize        int64 = 8 << 20
+	DefaultChunkConcurrency int   = 4
+)
+
+type fileChunk struct {
+	start int64
+	end   int64
+}
+
+type offsetWriter struct {
+	fd     *os.File
+	offset int64
+}
+
+func (w *offsetWriter) Write(p []byte) (int, error) {
+
+	n, err := w.fd.WriteAt(p, w.offset)
+	w.offset += int64(n)
+	return n, err
+}
+
+func GetFdChunked(ctx context.Context, fd *os.File, path string, query url.Values, headers map[string][]string, chunkSize int64, concurrency int) error {
+
+	return DefaultClient.GetFdChunked(ctx, fd, path, query, headers, chunkSize, concurrency)
+}
+
+func (client *HttpClient) GetFdChunked(ctx context.Context, fd *os.File, path string, query url.Values, headers map[string][]string, chunkSize int64, concurrency int) error {
+
+	if fd == nil {
+		return fmt.Errorf("client get file fd invalid, %s", path)
+	}
+
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+
+	if concurrency <= 0 {
+		concurrency = DefaultChunkConcurrency
+	}
+
+	fn := progressFunc(ctx, downloadProgressKey)
+	ctx = WithDownloadProgress(ctx, nil)
+	resp, err := client.Get(ctx, path, query, chunkHeaders(headers, fileChunk{start: 0, end: chunkSize - 1}))
+	if err != nil {
+		return err
+	}
+
+	statusCode := resp.StatusCode()
+	if statusCode == http.StatusOK {
+		defer resp.Close()
+		return copyWhole(fd, resp, fn)
+	}
+
+	if statusCode != http.StatusPartialContent {
+		resp.Close()
+		return fmt.Errorf("client get file fail %d, %s", statusCode, resp.Status())
+	}
+
+	start, end, total, ok := parseContentRange(resp.Header("Content-Range"))
+	if !ok || start != 0 || total < 0 {
+		resp.Close()
+		return fmt.Errorf("client get file range invalid, %s", resp.Header("Content-Range"))
+	}
+
+	validator := rangeValidator(resp)
+	if validator == "" && end+1 < total {
+		resp.Close()
+		if resp, err = client.Get(ctx, path, query, headers); err != nil {
+			return err
+		}
+
+		defer resp.Close()
+		if resp.StatusCode() != http.StatusOK {
+			return fmt.Errorf("client get file fail %d, %s", resp.StatusCode(), resp.Status())
+		}
+		return copyWhole(fd, resp, fn)
+	}
+
+	if err := fd.Truncate(total); err != nil {
+		resp.Close()
+		return err
+	}
+
+	var tracker *progressTracker
+	if fn != nil {
+		tracker = newProgressTracker(fn, total)
+	}
+
+	err = copyChunk(fd, resp, fileChunk{start: start, end: end}, tracker)
+	resp.Close()
+	if err != nil {
+		return err
+	}
+
+	chunks := make(chan fileChunk)
+	chunkCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		chunkErr error
+	)
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chunk := range chunks {
+				if err := client.getFdChunk(chunkCtx, fd, path, query, headers, chunk, total, validator, tracker); err != nil {
+					once.Do(func() {
+						chunkErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+	for offset := end + 1; offset < total; offset += chunkSize {
+		chunk := fileChunk{start: offset, end: offset + chunkSize - 1}
+		if chunk.end >= total {
+			chunk.end = total - 1
+		}
+
+		select {
+		case chunks <- chunk:
+			continue
+		case <-chunkCtx.Done():
+		}
+		break
+	}
+
+	close(chunks)
+	wg.Wait()
+	if chunkErr != nil {
+		return chunkErr
+	}
+	return ctx.Err()
+}
+
+func (client *HttpClient) getFdChunk(ctx context.Context, fd *os.File, path string, query url.Values, headers map[string][]string, chunk fileChunk, total int64, validator string, tracker *progressTracker) error {
+
+	rangeHeaders := chunkHeaders(headers, chunk)
+	rangeHeaders["If-Range"] = []string{validator}
+	resp, err := client.Get(ctx, path, query, rangeHeaders)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Close()
+	statusCode := resp.StatusCode()
+	if statusCode == http.StatusOK {
+		return fmt.Errorf("client get file changed during download, %s", path)
+	}
+
+	if statusCode != http.StatusPartialContent {
+		return fmt.Errorf("client get file chunk fail %d, %s", statusCode, resp.Status())
+	}
+
+	start, end, size, ok := parseContentRange(resp.Header("Content-Range"))
+	if !ok || start != chunk.start || end != chunk.end || size != total {
+		return fmt.Errorf("client get file range invalid, %s", resp.Header("Content-Range"))
+	}
+	return copyChunk(fd, resp, chunk, tracker)
+}
+
+func copyWhole(fd *os.File, resp *HttpResponse, fn ProgressFunc) error {
+
+	if err := fd.Truncate(0); err != nil {
+		return err
+	}
+
+	var reader io.Reader = resp.body
+	if fn != nil {
+		reader = &progressReader{ReadCloser: resp.body, tracker: newProgressTracker(fn, resp.contentLength())}
+	}
+	_, err := io.Copy(&offsetWriter{fd: fd, offset: 0}, reader)
+	return err
+}
+
+func copyChunk(fd *os.File, resp *HttpResponse, chunk fileChunk, tracker *progressTracker) error {
+
+	size := chunk.end - chunk.start + 1
+	reader := io.LimitReader(resp.body, size)
+	if tracker != nil {
+		reader = &progressReader{ReadCloser: ioutil.NopCloser(reader), tracker: tracker}
+	}
+
+	written, err := io.Copy(&offsetWriter{fd: fd, offset: chunk.start}, reader)
+	if err != nil {
+		return err
+	}
+
+	if written != size {
+		return fmt.Errorf("client get file chunk short, %d of %d bytes", written, size)
+	}
+	return nil
+}
+
+func chunkHeaders(headers map[string][]string, chunk fileChunk) map[string][]string {
+
+	rangeHeaders := make(map[string][]string)
+	for key, value := range headers {
+		rangeHeaders[key] = value
+	}
+	rangeHeaders["Range"] = []string{fmt.Sprintf("bytes=%d-%d", chunk.start, chunk.end)}
+	return rangeHeaders
+}
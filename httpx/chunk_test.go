@@ -0,0 +1,89 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetFdChunked(t *testing.T) {
+	content := []byte(strings.Repeat("humpback", 1000))
+	var (
+		mutex  sync.Mutex
+		ranges []string
+		etag   atomic.Value
+	)
+	etag.Store(`"v1"`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		ranges = append(ranges, r.Header.Get("Range")+" "+r.Header.Get("If-Range"))
+		mutex.Unlock()
+		if value := etag.Load().(string); value != "" {
+			w.Header().Set("ETag", value)
+		}
+		http.ServeContent(w, r, "artifact", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	fd, err := ioutil.TempFile("", "httpx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(fd.Name())
+	defer fd.Close()
+
+	read := func() []byte {
+		data, err := ioutil.ReadFile(fd.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	if err := NewClient().GetFdChunked(context.Background(), fd, server.URL, nil, nil, 1000, 4); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(read(), content) {
+		t.Error("chunked content mismatch")
+	}
+	if len(ranges) != 8 || ranges[0] != "bytes=0-999 " {
+		t.Errorf("unexpected range requests %v", ranges)
+	}
+	for _, value := range ranges[1:] {
+		if !strings.HasSuffix(value, ` "v1"`) {
+			t.Errorf("chunk request without validator, %s", value)
+		}
+	}
+
+	ranges = nil
+	etag.Store("")
+	if err := NewClient().GetFdChunked(context.Background(), fd, server.URL, nil, nil, 1000, 4); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(read(), content) {
+		t.Error("unvalidated content mismatch")
+	}
+	if len(ranges) != 2 || ranges[1] != " " {
+		t.Errorf("unexpected unvalidated requests %v", ranges)
+	}
+
+	etag.Store(`"v1"`)
+	client := NewClient().Use(func(next Handler) Handler {
+		return func(request *http.Request) (*http.Response, error) {
+			response, err := next(request)
+			etag.Store(`"v2"`)
+			return response, err
+		}
+	})
+	if err := client.GetFdChunked(context.Background(), fd, server.URL, nil, nil, 1000, 4); err == nil {
+		t.Error("expected changed file error")
+	}
+}
@@ -1,6 +1,7 @@
 package httpx
 
 import "golang.org/x/net/proxy"
+import "gopkg.in/yaml.v2"
 
 import (
 	"bytes"
@@ -8,11 +9,13 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"encoding/xml"
+	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"net/url"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -51,11 +54,8 @@ type httpBuffer struct {
 }
 
 type HttpClient struct {
-	c       *http.Client
-	pool    *sync.Pool
-	auth    basicAuth
-	cookies []*http.Cookie
-	headers map[string]string
+	mutex  sync.Mutex
+	config atomic.Value
 }
 
 func NewClient() *HttpClient {
@@ -72,26 +72,22 @@ func NewWithClient(client *http.Client) *HttpClient {
 		client = http.DefaultClient
 	}
 
-	return &HttpClient{
-		c:       client,
-		pool:    nil,
-		auth:    basicAuth{},
-		cookies: make([]*http.Cookie, 0),
-		headers: make(map[string]string),
-	}
+	config := newClientConfig()
+	config.c = client
+	httpClient := &HttpClient{}
+	httpClient.config.Store(config)
+	return httpClient
 }
 
 func (client *HttpClient) RawClient() *http.Client {
 
-	return client.c
+	return client.load().c
 }
 
 func (client *HttpClient) Close() {
 
-	if client.c != nil {
-		if transport, ok := client.c.Transport.(*http.Transport); ok {
-			transport.CloseIdleConnections()
-		}
+	if transport, ok := client.load().c.Transport.(*http.Transport); ok {
+		transport.CloseIdleConnections()
 	}
 }
 
@@ -100,82 +96,104 @@ func (client *HttpClient) UsePool(pool *sync.Pool) *HttpClient {
 	if pool == nil {
 		pool = DefaultPool
 	}
-	client.pool = pool
-	return client
+
+	return client.update(func(config *clientConfig) {
+		config.pool = pool
+	})
 }
 
 func (client *HttpClient) GetTransport() *http.Transport {
 
-	if client.c != nil {
-		if transport, ok := client.c.Transport.(*http.Transport); ok {
-			return transport
-		}
+	if transport, ok := client.load().c.Transport.(*http.Transport); ok {
+		return transport
 	}
 	return http.DefaultTransport.(*http.Transport)
 }
 
-func (client *HttpClient) SetTransport(transport *http.Transport) *HttpClient {
+func (client *HttpClient) SetTransport(transport http.RoundTripper) *HttpClient {
 
-	if client.c != nil {
-		if transport == nil {
-			transport = DefaultTransport
-		}
-		client.c.Transport = transport
+	if t, ok := transport.(*http.Transport); transport == nil || (ok && t == nil) {
+		transport = DefaultTransport
 	}
-	return client
+
+	return client.update(func(config *clientConfig) {
+		c := *config.c
+		c.Transport = transport
+		config.c = &c
+	})
 }
 
 func (client *HttpClient) SetBasicAuth(username string, password string) *HttpClient {
 
-	client.auth = basicAuth{
-		UserName: username,
-		Password: password,
-	}
-	return client
+	return client.update(func(config *clientConfig) {
+		config.auth = basicAuth{
+			UserName: username,
+			Password: password,
+		}
+	})
 }
 
 func (client *HttpClient) SetHeader(key string, value string) *HttpClient {
 
-	client.headers[key] = value
-	return client
+	return client.update(func(config *clientConfig) {
+		config.headers[key] = value
+	})
 }
 
 func (client *HttpClient) SetHeaders(headers map[string]string) *HttpClient {
 
-	for key, value := range headers {
-		client.headers[key] = value
-	}
-	return client
+	return client.update(func(config *clientConfig) {
+		for key, value := range headers {
+			config.headers[key] = value
+		}
+	})
 }
 
 func (client *HttpClient) SetCookie(cookie *http.Cookie) *HttpClient {
 
-	client.cookies = append(client.cookies, cookie)
-	return client
+	return client.update(func(config *clientConfig) {
+		config.cookies = append(config.cookies, cookie)
+	})
 }
 
 func (client *HttpClient) SetCookies(cookies []*http.Cookie) *HttpClient {
 
-	client.cookies = append(client.cookies, cookies...)
-	return client
+	return client.update(func(config *clientConfig) {
+		config.cookies = append(config.cookies, cookies...)
+	})
 }
 
 func (client *HttpClient) SetProxy(proxy *url.URL) *HttpClient {
 
-	client.GetTransport().Proxy = http.ProxyURL(proxy)
+	client.updateTransport(func(transport *http.Transport) {
+		transport.Proxy = http.ProxyURL(proxy)
+	})
 	return client
 }
 
 func (client *HttpClient) SetSocks5(network string, addr string, auth *proxy.Auth, forward proxy.Dialer) *HttpClient {
 
-	dialer, _ := proxy.SOCKS5(network, addr, auth, forward)
-	client.GetTransport().Dial = dialer.Dial
+	dialer, err := proxy.SOCKS5(network, addr, auth, forward)
+	if err != nil {
+		return client
+	}
+
+	client.updateTransport(func(transport *http.Transport) {
+		if contextDialer, ok := dialer.(proxy.ContextDialer); ok {
+			transport.DialContext = contextDialer.DialContext
+		} else {
+			transport.DialContext = nil
+			transport.Dial = dialer.Dial
+		}
+	})
 	return client
 }
 
 func (client *HttpClient) SetTLSClientConfig(tlsConfig *tls.Config) *HttpClient {
 
-	client.GetTransport().TLSClientConfig = tlsConfig
+	client.updateTransport(func(transport *http.Transport) {
+		transport.TLSClientConfig = tlsConfig
+	})
 	return client
 }
 
@@ -209,6 +227,11 @@ func PutXML(ctx context.Context, path string, query url.Values, object interface
 	return DefaultClient.PutXML(ctx, path, query, object, headers)
 }
 
+func PutYAML(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*HttpResponse, error) {
+
+	return DefaultClient.PutYAML(ctx, path, query, object, headers)
+}
+
 func Post(ctx context.Context, path string, query url.Values, data io.Reader, headers map[string][]string) (*HttpResponse, error) {
 
 	return DefaultClient.Post(ctx, path, query, data, headers)
@@ -224,6 +247,11 @@ func PostXML(ctx context.Context, path string, query url.Values, object interfac
 	return DefaultClient.PostXML(ctx, path, query, object, headers)
 }
 
+func PostYAML(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*HttpResponse, error) {
+
+	return DefaultClient.PostYAML(ctx, path, query, object, headers)
+}
+
 func Patch(ctx context.Context, path string, query url.Values, data io.Reader, headers map[string][]string) (*HttpResponse, error) {
 
 	return DefaultClient.Patch(ctx, path, query, data, headers)
@@ -239,6 +267,11 @@ func PatchXML(ctx context.Context, path string, query url.Values, object interfa
 	return DefaultClient.PatchXML(ctx, path, query, object, headers)
 }
 
+func PatchYAML(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*HttpResponse, error) {
+
+	return DefaultClient.PatchYAML(ctx, path, query, object, headers)
+}
+
 func Delete(ctx context.Context, path string, query url.Values, headers map[string][]string) (*HttpResponse, error) {
 
 	return DefaultClient.Delete(ctx, path, query, headers)
@@ -291,11 +324,11 @@ func (client *HttpClient) Put(ctx context.Context, path string, query url.Values
 func (client *HttpClient) PutJSON(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*HttpResponse, error) {
 
 	httpBuffer, err := client.encodeJson(object, headers)
-	defer client.putBuffer(httpBuffer.Data)
 	if err != nil {
 		return nil, err
 	}
 
+	defer client.putBuffer(httpBuffer.Data)
 	return client.sendRequest(ctx, &HttpRequest{
 		Method:  http.MethodPut,
 		RawURL:  path,
@@ -308,11 +341,28 @@ func (client *HttpClient) PutJSON(ctx context.Context, path string, query url.Va
 func (client *HttpClient) PutXML(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*HttpResponse, error) {
 
 	httpBuffer, err := client.encodeXml(object, headers)
+	if err != nil {
+		return nil, err
+	}
+
 	defer client.putBuffer(httpBuffer.Data)
+	return client.sendRequest(ctx, &HttpRequest{
+		Method:  http.MethodPut,
+		RawURL:  path,
+		Query:   query,
+		Data:    httpBuffer.Data,
+		Headers: httpBuffer.Headers,
+	})
+}
+
+func (client *HttpClient) PutYAML(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*HttpResponse, error) {
+
+	httpBuffer, err := client.encodeYaml(object, headers)
 	if err != nil {
 		return nil, err
 	}
 
+	defer client.putBuffer(httpBuffer.Data)
 	return client.sendRequest(ctx, &HttpRequest{
 		Method:  http.MethodPut,
 		RawURL:  path,
@@ -336,11 +386,11 @@ func (client *HttpClient) Post(ctx context.Context, path string, query url.Value
 func (client *HttpClient) PostJSON(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*HttpResponse, error) {
 
 	httpBuffer, err := client.encodeJson(object, headers)
-	defer client.putBuffer(httpBuffer.Data)
 	if err != nil {
 		return nil, err
 	}
 
+	defer client.putBuffer(httpBuffer.Data)
 	return client.sendRequest(ctx, &HttpRequest{
 		Method:  http.MethodPost,
 		RawURL:  path,
@@ -353,11 +403,28 @@ func (client *HttpClient) PostJSON(ctx context.Context, path string, query url.V
 func (client *HttpClient) PostXML(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*HttpResponse, error) {
 
 	httpBuffer, err := client.encodeXml(object, headers)
+	if err != nil {
+		return nil, err
+	}
+
 	defer client.putBuffer(httpBuffer.Data)
+	return client.sendRequest(ctx, &HttpRequest{
+		Method:  http.MethodPost,
+		RawURL:  path,
+		Query:   query,
+		Data:    httpBuffer.Data,
+		Headers: httpBuffer.Headers,
+	})
+}
+
+func (client *HttpClient) PostYAML(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*HttpResponse, error) {
+
+	httpBuffer, err := client.encodeYaml(object, headers)
 	if err != nil {
 		return nil, err
 	}
 
+	defer client.putBuffer(httpBuffer.Data)
 	return client.sendRequest(ctx, &HttpRequest{
 		Method:  http.MethodPost,
 		RawURL:  path,
@@ -381,11 +448,11 @@ func (client *HttpClient) Patch(ctx context.Context, path string, query url.Valu
 func (client *HttpClient) PatchJSON(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*HttpResponse, error) {
 
 	httpBuffer, err := client.encodeJson(object, headers)
-	defer client.putBuffer(httpBuffer.Data)
 	if err != nil {
 		return nil, err
 	}
 
+	defer client.putBuffer(httpBuffer.Data)
 	return client.sendRequest(ctx, &HttpRequest{
 		Method:  http.MethodPatch,
 		RawURL:  path,
@@ -398,11 +465,28 @@ func (client *HttpClient) PatchJSON(ctx context.Context, path string, query url.
 func (client *HttpClient) PatchXML(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*HttpResponse, error) {
 
 	httpBuffer, err := client.encodeXml(object, headers)
+	if err != nil {
+		return nil, err
+	}
+
 	defer client.putBuffer(httpBuffer.Data)
+	return client.sendRequest(ctx, &HttpRequest{
+		Method:  http.MethodPatch,
+		RawURL:  path,
+		Query:   query,
+		Data:    httpBuffer.Data,
+		Headers: httpBuffer.Headers,
+	})
+}
+
+func (client *HttpClient) PatchYAML(ctx context.Context, path string, query url.Values, object interface{}, headers map[string][]string) (*HttpResponse, error) {
+
+	httpBuffer, err := client.encodeYaml(object, headers)
 	if err != nil {
 		return nil, err
 	}
 
+	defer client.putBuffer(httpBuffer.Data)
 	return client.sendRequest(ctx, &HttpRequest{
 		Method:  http.MethodPatch,
 		RawURL:  path,
@@ -427,6 +511,7 @@ func (client *HttpClient) encodeJson(object interface{}, headers map[string][]st
 
 	data := client.getBuffer()
 	if err := json.NewEncoder(data).Encode(object); err != nil {
+		client.putBuffer(data)
 		return nil, err
 	}
 
@@ -435,16 +520,17 @@ func (client *HttpClient) encodeJson(object interface{}, headers map[string][]st
 	}
 
 	headers["Content-Type"] = []string{"application/json;charset=utf-8"}
-	return &httpBuffer{
+	return client.compressBuffer(&httpBuffer{
 		Data:    data,
 		Headers: headers,
-	}, nil
+	})
 }
 
 func (client *HttpClient) encodeXml(object interface{}, headers map[string][]string) (*httpBuffer, error) {
 
 	data := client.getBuffer()
 	if err := xml.NewEncoder(data).Encode(object); err != nil {
+		client.putBuffer(data)
 		return nil, err
 	}
 
@@ -453,26 +539,57 @@ func (client *HttpClient) encodeXml(object interface{}, headers map[string][]str
 	}
 
 	headers["Content-Type"] = []string{"application/xml;charset=utf-8"}
-	return &httpBuffer{
+	return client.compressBuffer(&httpBuffer{
+		Data:    data,
+		Headers: headers,
+	})
+}
+
+func (client *HttpClient) encodeYaml(object interface{}, headers map[string][]string) (*httpBuffer, error) {
+
+	buf, err := marshalYaml(object)
+	if err != nil {
+		return nil, err
+	}
+
+	data := client.getBuffer()
+	data.Write(buf)
+	if headers == nil {
+		headers = make(map[string][]string)
+	}
+
+	headers["Content-Type"] = []string{"application/yaml;charset=utf-8"}
+	return client.compressBuffer(&httpBuffer{
 		Data:    data,
 		Headers: headers,
-	}, nil
+	})
+}
+
+func marshalYaml(object interface{}) (buf []byte, err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			buf, err = nil, fmt.Errorf("client yaml encode invalid, %v", r)
+		}
+	}()
+	return yaml.Marshal(object)
 }
 
 func (client *HttpClient) getBuffer() *bytes.Buffer {
 
-	if client.pool == nil {
+	pool := client.load().pool
+	if pool == nil {
 		return bytes.NewBuffer([]byte{})
 	}
 
-	buf := client.pool.Get().(*bytes.Buffer)
+	buf := pool.Get().(*bytes.Buffer)
 	buf.Reset()
 	return buf
 }
 
 func (client *HttpClient) putBuffer(buf *bytes.Buffer) {
 
-	if client.pool != nil {
-		client.pool.Put(buf)
+	if pool := client.load().pool; pool != nil {
+		pool.Put(buf)
 	}
 }
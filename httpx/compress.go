@@ -0,0 +1,220 @@
+package httpx
+
+import "github.com/klauspost/compress/zstd"
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	EncodingGzip    = "gzip"
+	EncodingDeflate = "deflate"
+	EncodingZstd    = "zstd"
+)
+
+const DefaultCompressThreshold = 1 << 10
+
+type compression struct {
+	encoding  string
+	threshold int
+}
+
+type decoderFunc func(reader *bufio.Reader) (io.Reader, io.Closer, error)
+
+type decodedBody struct {
+	body    io.ReadCloser
+	decode  decoderFunc
+	reader  io.Reader
+	decoder io.Closer
+	err     error
+}
+
+func (body *decodedBody) Read(p []byte) (int, error) {
+
+	if body.reader == nil && body.err == nil {
+		reader := bufio.NewReader(body.body)
+		if _, err := reader.Peek(1); err != nil {
+			body.err = err
+		} else {
+			body.reader, body.decoder, body.err = body.decode(reader)
+		}
+	}
+
+	if body.err != nil {
+		return 0, body.err
+	}
+	return body.reader.Read(p)
+}
+
+func (body *decodedBody) Close() error {
+
+	if body.decoder != nil {
+		body.decoder.Close()
+	}
+	return body.body.Close()
+}
+
+func (client *HttpClient) SetRequestCompression(encoding string, threshold int) (*HttpClient, error) {
+
+	encoding = strings.ToLower(encoding)
+	switch encoding {
+	case "", EncodingGzip, EncodingDeflate, EncodingZstd:
+	default:
+		return nil, fmt.Errorf("client compression encoding invalid, %s", encoding)
+	}
+
+	if threshold < 0 {
+		threshold = DefaultCompressThreshold
+	}
+
+	return client.update(func(config *clientConfig) {
+		config.compression = compression{
+			encoding:  encoding,
+			threshold: threshold,
+		}
+	}), nil
+}
+
+func (client *HttpClient) SetResponseDecompression(enabled bool) *HttpClient {
+
+	return client.update(func(config *clientConfig) {
+		config.decompress = enabled
+	})
+}
+
+func (client *HttpClient) compressBuffer(buffer *httpBuffer) (*httpBuffer, error) {
+
+	compression := client.load().compression
+	encoding := compression.encoding
+	if encoding == "" || buffer.Data.Len() < compression.threshold {
+		return buffer, nil
+	}
+
+	var (
+		writer io.WriteCloser
+		err    error
+	)
+
+	data := client.getBuffer()
+	switch encoding {
+	case EncodingGzip:
+		writer = gzip.NewWriter(data)
+	case EncodingDeflate:
+		writer = zlib.NewWriter(data)
+	case EncodingZstd:
+		writer, err = zstd.NewWriter(data)
+	default:
+		err = fmt.Errorf("client compression encoding invalid, %s", encoding)
+	}
+
+	if err != nil {
+		client.putBuffer(data)
+		return buffer, err
+	}
+
+	if _, err := buffer.Data.WriteTo(writer); err != nil {
+		client.putBuffer(data)
+		return buffer, err
+	}
+
+	if err := writer.Close(); err != nil {
+		client.putBuffer(data)
+		return buffer, err
+	}
+
+	client.putBuffer(buffer.Data)
+	buffer.Data = data
+	buffer.Headers["Content-Encoding"] = []string{encoding}
+	return buffer, nil
+}
+
+func (config *clientConfig) acceptEncoding(request *http.Request) {
+
+	if config.decompress && request.Header.Get("Accept-Encoding") == "" {
+		request.Header.Set("Accept-Encoding", "gzip, deflate, zstd")
+	}
+}
+
+func (config *clientConfig) decodeResponse(response *http.Response) {
+
+	if !config.decompress || response.Uncompressed || !bodyAllowed(response) {
+		return
+	}
+
+	var decode decoderFunc
+	switch strings.ToLower(strings.TrimSpace(response.Header.Get("Content-Encoding"))) {
+	case EncodingGzip, "x-gzip":
+		decode = decodeGzip
+	case EncodingDeflate:
+		decode = decodeDeflate
+	case EncodingZstd:
+		decode = decodeZstd
+	default:
+		return
+	}
+
+	response.Body = &decodedBody{body: response.Body, decode: decode}
+	response.Header.Del("Content-Encoding")
+	response.Header.Del("Content-Length")
+	response.ContentLength = -1
+	response.Uncompressed = true
+}
+
+func decodeGzip(reader *bufio.Reader) (io.Reader, io.Closer, error) {
+
+	decoder, err := gzip.NewReader(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return decoder, decoder, nil
+}
+
+func decodeDeflate(reader *bufio.Reader) (io.Reader, io.Closer, error) {
+
+	if header, err := reader.Peek(2); err == nil && isZlibHeader(header) {
+		decoder, err := zlib.NewReader(reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		return decoder, decoder, nil
+	}
+
+	decoder := flate.NewReader(reader)
+	return decoder, decoder, nil
+}
+
+func decodeZstd(reader *bufio.Reader) (io.Reader, io.Closer, error) {
+
+	decoder, err := zstd.NewReader(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return decoder, decoder.IOReadCloser(), nil
+}
+
+func bodyAllowed(response *http.Response) bool {
+
+	if response.Request != nil && response.Request.Method == http.MethodHead {
+		return false
+	}
+
+	switch {
+	case response.StatusCode >= 100 && response.StatusCode < 200:
+		return false
+	case response.StatusCode == http.StatusNoContent, response.StatusCode == http.StatusNotModified:
+		return false
+	}
+	return true
+}
+
+func isZlibHeader(header []byte) bool {
+
+	return header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0
+}
@@ -0,0 +1,184 @@
+package httpx
+
+import "github.com/klauspost/compress/zstd"
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func encodeTest(t *testing.T, encoding string, data []byte) []byte {
+	var (
+		buffer bytes.Buffer
+		writer io.WriteCloser
+		err    error
+	)
+	switch encoding {
+	case EncodingGzip:
+		writer = gzip.NewWriter(&buffer)
+	case EncodingDeflate:
+		writer = zlib.NewWriter(&buffer)
+	case "flate":
+		writer, err = flate.NewWriter(&buffer, flate.DefaultCompression)
+	case EncodingZstd:
+		writer, err = zstd.NewWriter(&buffer)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.Write(data)
+	writer.Close()
+	return buffer.Bytes()
+}
+
+func decodeTest(encoding string, reader io.Reader) ([]byte, error) {
+	switch encoding {
+	case EncodingGzip:
+		decoder, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.ReadAll(decoder)
+	case EncodingDeflate:
+		decoder, err := zlib.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.ReadAll(decoder)
+	case EncodingZstd:
+		decoder, err := zstd.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		defer decoder.Close()
+		return ioutil.ReadAll(decoder)
+	}
+	return ioutil.ReadAll(reader)
+}
+
+func TestRequestCompression(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := decodeTest(r.Header.Get("Content-Encoding"), r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("X-Encoding", r.Header.Get("Content-Encoding"))
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	payload := strings.Repeat("humpback", 512)
+	for _, encoding := range []string{EncodingGzip, EncodingDeflate, EncodingZstd} {
+		client, err := NewClient().SetRequestCompression(encoding, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, body := range []string{payload, "humpback"} {
+			resp, err := client.PostJSON(context.Background(), server.URL, nil, body, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := encoding
+			if body == "humpback" {
+				expected = ""
+			}
+			if data := resp.String(); data != `"`+body+`"`+"\n" || resp.Header("X-Encoding") != expected {
+				t.Errorf("%s unexpected round trip %d bytes encoded %q", encoding, len(data), resp.Header("X-Encoding"))
+			}
+			resp.Close()
+		}
+	}
+}
+
+func TestResponseDecompression(t *testing.T) {
+	payload := []byte(strings.Repeat("humpback", 512))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip, deflate, zstd" {
+			t.Errorf("unexpected accept encoding %q", r.Header.Get("Accept-Encoding"))
+		}
+		encoding := strings.TrimPrefix(r.URL.Path, "/")
+		switch encoding {
+		case "empty":
+			w.Header().Set("Content-Encoding", EncodingGzip)
+		case "flate":
+			w.Header().Set("Content-Encoding", EncodingDeflate)
+			w.Write(encodeTest(t, encoding, payload))
+		default:
+			w.Header().Set("Content-Encoding", encoding)
+			w.Write(encodeTest(t, encoding, payload))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient().SetResponseDecompression(true)
+	for _, encoding := range []string{EncodingGzip, EncodingDeflate, "flate", EncodingZstd, "empty"} {
+		resp, err := client.Get(context.Background(), server.URL+"/"+encoding, nil, nil)
+		if err != nil {
+			t.Fatalf("%s %v", encoding, err)
+		}
+		data, err := resp.Bytes()
+		resp.Close()
+		if err != nil {
+			t.Fatalf("%s %v", encoding, err)
+		}
+		expected := payload
+		if encoding == "empty" {
+			expected = []byte{}
+		}
+		if !bytes.Equal(data, expected) || resp.Header("Content-Encoding") != "" {
+			t.Errorf("%s unexpected decoded body %d bytes", encoding, len(data))
+		}
+	}
+}
+
+func TestDecodeResponseWithoutBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", EncodingGzip)
+		switch r.URL.Path {
+		case "/nocontent":
+			w.WriteHeader(http.StatusNoContent)
+		case "/notmodified":
+			w.WriteHeader(http.StatusNotModified)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient().SetResponseDecompression(true)
+	resp, err := client.Head(context.Background(), server.URL, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Close()
+
+	for _, path := range []string{"/nocontent", "/notmodified"} {
+		resp, err := client.Get(context.Background(), server.URL+path, nil, nil)
+		if err != nil {
+			t.Fatalf("%s %v", path, err)
+		}
+		resp.Close()
+	}
+}
+
+func TestSetRequestCompression(t *testing.T) {
+	if _, err := NewClient().SetRequestCompression("br", 0); err == nil {
+		t.Error("invalid encoding accepted")
+	}
+
+	client, err := NewClient().SetRequestCompression("GZIP", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoding := client.load().compression.encoding; encoding != EncodingGzip {
+		t.Errorf("unexpected encoding %s", encoding)
+	}
+}
@@ -0,0 +1,121 @@
+package httpx
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+type clientConfig struct {
+	c           *http.Client
+	pool        *sync.Pool
+	auth        basicAuth
+	cookies     []*http.Cookie
+	jar         http.CookieJar
+	headers     map[string]string
+	retry       *RetryPolicy
+	middlewares []Middleware
+	breakers    *circuitBreakers
+	statuserror bool
+	compression compression
+	decompress  bool
+	cache       Cache
+	limits      *clientLimits
+	baseurl     string
+	tokens      *ReuseTokenSource
+	endpoints   *endpointPool
+	tracehook   TraceFunc
+	maxbodysize int64
+}
+
+func newClientConfig() *clientConfig {
+
+	return &clientConfig{
+		pool:        nil,
+		auth:        basicAuth{},
+		cookies:     make([]*http.Cookie, 0),
+		headers:     make(map[string]string),
+		middlewares: make([]Middleware, 0),
+	}
+}
+
+func (config *clientConfig) clone() *clientConfig {
+
+	cloned := *config
+	cloned.cookies = append(make([]*http.Cookie, 0, len(config.cookies)), config.cookies...)
+	cloned.middlewares = append(make([]Middleware, 0, len(config.middlewares)), config.middlewares...)
+	cloned.headers = make(map[string]string, len(config.headers))
+	for key, value := range config.headers {
+		cloned.headers[key] = value
+	}
+	return &cloned
+}
+
+func (client *HttpClient) load() *clientConfig {
+
+	return client.config.Load().(*clientConfig)
+}
+
+func (client *HttpClient) update(fn func(config *clientConfig)) *HttpClient {
+
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+	config := client.load().clone()
+	fn(config)
+	client.config.Store(config)
+	return client
+}
+
+func (client *HttpClient) Clone() *HttpClient {
+
+	config := client.load().clone()
+	c := *config.c
+	config.c = &c
+	cloned := &HttpClient{}
+	cloned.config.Store(config)
+	return cloned
+}
+
+func (client *HttpClient) updateTransport(fn func(transport *http.Transport)) error {
+
+	var err error
+	client.update(func(config *clientConfig) {
+		var transport *http.Transport
+		switch t := config.c.Transport.(type) {
+		case nil:
+			transport = http.DefaultTransport.(*http.Transport).Clone()
+		case *http.Transport:
+			transport = t.Clone()
+		default:
+			err = fmt.Errorf("client transport %T not supported", t)
+			return
+		}
+
+		fn(transport)
+		c := *config.c
+		c.Transport = transport
+		config.c = &c
+	})
+	return err
+}
+
+func (client *HttpClient) With(fn func(client *HttpClient)) *HttpClient {
+
+	cloned := client.Clone()
+	if fn != nil {
+		fn(cloned)
+	}
+	return cloned
+}
+
+func (client *HttpClient) SetCookieJar(jar http.CookieJar) *HttpClient {
+
+	return client.update(func(config *clientConfig) {
+		config.jar = jar
+	})
+}
+
+func (client *HttpClient) CookieJar() http.CookieJar {
+
+	return client.load().jar
+}
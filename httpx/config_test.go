@@ -0,0 +1,68 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestCloneTransport(t *testing.T) {
+	parent := NewClient()
+	proxy, _ := url.Parse("http://127.0.0.1:3128")
+	cloned := parent.With(func(client *HttpClient) {
+		client.SetProxy(proxy)
+	})
+
+	if parent.RawClient() == cloned.RawClient() {
+		t.Errorf("expected cloned http client")
+	}
+	if parent.RawClient().Transport != http.DefaultTransport || http.DefaultTransport.(*http.Transport).Proxy == nil {
+		t.Errorf("expected parent transport unchanged")
+	}
+
+	request, _ := http.NewRequest(http.MethodGet, "http://humpback/", nil)
+	if proxyURL, err := cloned.GetTransport().Proxy(request); err != nil || proxyURL.String() != proxy.String() {
+		t.Errorf("unexpected cloned proxy %v %v", proxyURL, err)
+	}
+	if proxyURL, _ := parent.GetTransport().Proxy(request); proxyURL != nil && proxyURL.String() == proxy.String() {
+		t.Errorf("unexpected parent proxy %v", proxyURL)
+	}
+}
+
+func TestCookieJarRedirect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "humpback", Path: "/"})
+			http.Redirect(w, r, "/home", http.StatusFound)
+			return
+		}
+
+		cookie, err := r.Cookie("session")
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(cookie.Value))
+	}))
+	defer server.Close()
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewClient().SetCookieJar(jar)
+	for _, path := range []string{"/login", "/profile"} {
+		resp, err := client.Get(context.Background(), server.URL+path, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if body := resp.String(); resp.StatusCode() != http.StatusOK || body != "humpback" {
+			t.Errorf("%s unexpected response %d %q", path, resp.StatusCode(), body)
+		}
+		resp.Close()
+	}
+}
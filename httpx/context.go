@@ -0,0 +1,12 @@
+package httpx
+
+type contextKey int
+
+const (
+	uploadProgressKey contextKey = iota
+	downloadProgressKey
+	statusErrorKey
+	traceHookKey
+	maxBodySizeKey
+	tokenRequestKey
+)
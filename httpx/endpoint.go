@@ -0,0 +1,283 @@
+package httpx
+
+import "github.com/humpback/gounits/algorithm"
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+type Balance int
+
+const (
+	BalanceRoundRobin Balance = iota
+	BalanceConsistent
+)
+
+var DefaultEndpointCoolDown = 30 * time.Second
+
+type endpoint struct {
+	rawurl    string
+	host      string
+	downUntil time.Time
+}
+
+type endpointPool struct {
+	sync.Mutex
+	balance    Balance
+	cooldown   time.Duration
+	endpoints  []*endpoint
+	indexes    map[string]int
+	consistent *algorithm.Consistent
+	next       int
+	serverName string
+	base       *http.Transport
+	transport  *http.Transport
+}
+
+func newEndpointPool(endpoints []*endpoint, balance Balance, cooldown time.Duration) *endpointPool {
+
+	if len(endpoints) == 0 {
+		return nil
+	}
+
+	if cooldown <= 0 {
+		cooldown = DefaultEndpointCoolDown
+	}
+
+	pool := &endpointPool{
+		balance:   balance,
+		cooldown:  cooldown,
+		endpoints: endpoints,
+		indexes:   make(map[string]int),
+	}
+
+	if balance == BalanceConsistent {
+		pool.consistent = algorithm.NewConsisten(0)
+		for i, endpoint := range endpoints {
+			pool.indexes[endpoint.rawurl] = i
+			pool.consistent.Add(endpoint.rawurl)
+		}
+	}
+	return pool
+}
+
+func (client *HttpClient) SetEndpoints(endpoints []string, balance Balance) *HttpClient {
+
+	return client.SetEndpointsWithCoolDown(endpoints, balance, DefaultEndpointCoolDown)
+}
+
+func (client *HttpClient) SetEndpointsWithCoolDown(endpoints []string, balance Balance, cooldown time.Duration) *HttpClient {
+
+	pool := []*endpoint{}
+	for _, rawurl := range endpoints {
+		pool = append(pool, &endpoint{rawurl: rawurl})
+	}
+
+	return client.update(func(config *clientConfig) {
+		config.endpoints = newEndpointPool(pool, balance, cooldown)
+	})
+}
+
+func (client *HttpClient) ResolveEndpoints(ctx context.Context, rawurl string, balance Balance) error {
+
+	baseurl, err := url.Parse(rawurl)
+	if err != nil {
+		return fmt.Errorf("client endpoint url invalid, %s", err.Error())
+	}
+
+	hostname, port := baseurl.Hostname(), baseurl.Port()
+	addrs, err := net.DefaultResolver.LookupHost(ctx, hostname)
+	if err != nil {
+		return err
+	}
+
+	pool := []*endpoint{}
+	for _, addr := range addrs {
+		resolved := *baseurl
+		resolved.Host = addr
+		if port != "" {
+			resolved.Host = net.JoinHostPort(addr, port)
+		} else if net.ParseIP(addr).To4() == nil {
+			resolved.Host = "[" + addr + "]"
+		}
+		pool = append(pool, &endpoint{rawurl: resolved.String(), host: baseurl.Host})
+	}
+
+	endpoints := newEndpointPool(pool, balance, DefaultEndpointCoolDown)
+	if endpoints != nil {
+		endpoints.serverName = hostname
+	}
+
+	client.update(func(config *clientConfig) {
+		config.endpoints = endpoints
+	})
+	return nil
+}
+
+func (client *HttpClient) Endpoints() []string {
+
+	endpoints := []string{}
+	pool := client.load().endpoints
+	if pool == nil {
+		return endpoints
+	}
+
+	pool.Lock()
+	defer pool.Unlock()
+	now := time.Now()
+	for _, endpoint := range pool.endpoints {
+		if endpoint.downUntil.Before(now) {
+			endpoints = append(endpoints, endpoint.rawurl)
+		}
+	}
+	return endpoints
+}
+
+func (pool *endpointPool) order(key string) []*endpoint {
+
+	pool.Lock()
+	defer pool.Unlock()
+	start := 0
+	if pool.consistent != nil {
+		start = pool.indexes[pool.consistent.Get(key)]
+	} else {
+		start = pool.next % len(pool.endpoints)
+		pool.next = start + 1
+	}
+
+	now := time.Now()
+	healthy, unhealthy := []*endpoint{}, []*endpoint{}
+	for i := range pool.endpoints {
+		endpoint := pool.endpoints[(start+i)%len(pool.endpoints)]
+		if endpoint.downUntil.After(now) {
+			unhealthy = append(unhealthy, endpoint)
+		} else {
+			healthy = append(healthy, endpoint)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+func (pool *endpointPool) mark(endpoint *endpoint, failed bool) {
+
+	pool.Lock()
+	if failed {
+		endpoint.downUntil = time.Now().Add(pool.cooldown)
+	} else {
+		endpoint.downUntil = time.Time{}
+	}
+	pool.Unlock()
+}
+
+func (pool *endpointPool) client(c *http.Client) *http.Client {
+
+	base, ok := c.Transport.(*http.Transport)
+	if c.Transport == nil {
+		base, ok = http.DefaultTransport.(*http.Transport)
+	}
+
+	if !ok {
+		return c
+	}
+
+	pool.Lock()
+	if pool.base != base {
+		transport := base.Clone()
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.ServerName = pool.serverName
+		pool.base, pool.transport = base, transport
+	}
+	transport := pool.transport
+	pool.Unlock()
+
+	resolved := *c
+	resolved.Transport = transport
+	return &resolved
+}
+
+func (endpoint *endpoint) request(ctx context.Context, request *http.Request, rewind bool) (*http.Request, error) {
+
+	rawurl, err := joinURL(endpoint.rawurl, request.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	attemptRequest := request.Clone(ctx)
+	attemptRequest.URL = rawurl
+	attemptRequest.Host = endpoint.host
+	if rewind && request.GetBody != nil {
+		body, err := request.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		attemptRequest.Body = body
+	}
+	return attemptRequest, nil
+}
+
+func idempotent(method string) bool {
+
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func (client *HttpClient) doFailover(ctx context.Context, request *http.Request, state *requestState) (*http.Response, error) {
+
+	pool := state.config.endpoints
+	if pool == nil || request.URL.Scheme != "" || request.URL.Host != "" {
+		return client.doRetry(ctx, request, state)
+	}
+
+	rewindable := request.Body == nil || request.Body == http.NoBody || request.GetBody != nil
+	failover := idempotent(request.Method) && rewindable
+	endpoints := pool.order(request.URL.Path)
+	attempts := 0
+	defer func() {
+		state.attempts = attempts
+	}()
+
+	for i, endpoint := range endpoints {
+		attemptRequest, err := endpoint.request(ctx, request, i > 0)
+		if err != nil {
+			return nil, err
+		}
+
+		if pool.serverName != "" {
+			state.resolved = pool
+		}
+
+		response, err := client.doRetry(ctx, attemptRequest, state)
+		state.resolved = nil
+		attempts += state.attempts
+		if ctx.Err() != nil || errors.Is(err, ErrRateLimit) {
+			return response, err
+		}
+
+		failed := err != nil || response.StatusCode >= http.StatusInternalServerError
+		pool.mark(endpoint, failed)
+		if !failed || !failover || i == len(endpoints)-1 {
+			return response, err
+		}
+
+		if response != nil {
+			io.Copy(ioutil.Discard, response.Body)
+			response.Body.Close()
+		}
+	}
+	return nil, errors.New("client endpoints invalid.")
+}
@@ -0,0 +1,87 @@
+package httpx
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEndpointFailover(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer down.Close()
+
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/nodes" || (r.Method == http.MethodGet && r.URL.Query().Get("name") != "humpback") {
+			t.Errorf("unexpected url %s", r.URL)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer up.Close()
+
+	client := NewClient().SetEndpoints([]string{down.URL + "/v1", up.URL + "/v1"}, BalanceRoundRobin)
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(context.Background(), "/nodes?name=humpback", nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Close()
+		if resp.StatusCode() != http.StatusOK {
+			t.Errorf("unexpected status %d", resp.StatusCode())
+		}
+	}
+
+	if endpoints := client.Endpoints(); len(endpoints) != 1 || endpoints[0] != up.URL+"/v1" {
+		t.Errorf("unexpected healthy endpoints %v", endpoints)
+	}
+
+	resp, err := client.Post(context.Background(), "/nodes", nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Close()
+	if resp.StatusCode() != http.StatusOK {
+		t.Errorf("unexpected status %d", resp.StatusCode())
+	}
+}
+
+func TestResolvedEndpointServerName(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Host != "example.com" || r.TLS == nil || r.TLS.ServerName != "example.com" {
+			t.Errorf("unexpected host %s", r.Host)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	client := NewClient().SetTLSClientConfig(&tls.Config{RootCAs: pool})
+	for _, serverName := range []string{"example.com", "humpback.io"} {
+		endpoints := newEndpointPool([]*endpoint{{rawurl: server.URL, host: "example.com"}}, BalanceRoundRobin, 0)
+		endpoints.serverName = serverName
+		client.update(func(config *clientConfig) {
+			config.endpoints = endpoints
+		})
+
+		resp, err := client.Get(context.Background(), "/", nil, nil)
+		if serverName == "humpback.io" {
+			if err == nil {
+				t.Errorf("expected certificate name mismatch")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Close()
+	}
+}
@@ -0,0 +1,65 @@
+package httpx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+const StatusErrorBodySize int64 = 4 << 10
+
+type StatusError struct {
+	StatusCode int
+	Status     string
+	URL        string
+	Header     http.Header
+	Body       []byte
+}
+
+func (e *StatusError) Error() string {
+
+	status := e.Status
+	if status == "" {
+		status = fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+
+	if len(e.Body) > 0 {
+		return fmt.Sprintf("client request %s fail, %s: %s", e.URL, status, e.Body)
+	}
+	return fmt.Sprintf("client request %s fail, %s", e.URL, status)
+}
+
+func (client *HttpClient) SetStatusError(enabled bool) *HttpClient {
+
+	return client.update(func(config *clientConfig) {
+		config.statuserror = enabled
+	})
+}
+
+func WithStatusError(ctx context.Context, enabled bool) context.Context {
+
+	return context.WithValue(ctx, statusErrorKey, enabled)
+}
+
+func (config *clientConfig) statusError(ctx context.Context) bool {
+
+	if enabled, ok := ctx.Value(statusErrorKey).(bool); ok {
+		return enabled
+	}
+	return config.statuserror
+}
+
+func newStatusError(resp *HttpResponse) *StatusError {
+
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.body, StatusErrorBodySize))
+	resp.body.Close()
+	return &StatusError{
+		StatusCode: resp.statuscode,
+		Status:     resp.status,
+		URL:        resp.rawurl,
+		Header:     resp.header,
+		Body:       body,
+	}
+}
@@ -0,0 +1,47 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStatusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "node1")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("node not found"))
+	}))
+	defer server.Close()
+
+	client := NewClient().SetStatusError(true)
+	_, err := client.Get(context.Background(), server.URL+"/nodes/node1", nil, nil)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if statusErr.StatusCode != http.StatusNotFound || string(statusErr.Body) != "node not found" || statusErr.Header.Get("X-Request-Id") != "node1" {
+		t.Errorf("unexpected status error %+v", statusErr)
+	}
+	if message := err.Error(); message != "client request "+server.URL+"/nodes/node1 fail, 404 Not Found: node not found" {
+		t.Errorf("unexpected message %q", message)
+	}
+
+	resp, err := client.Get(WithStatusError(context.Background(), false), server.URL, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Close()
+	if resp.StatusCode() != http.StatusNotFound {
+		t.Errorf("unexpected status %d", resp.StatusCode())
+	}
+
+	if _, err := NewClient().Get(WithStatusError(context.Background(), true), server.URL, nil, nil); !errors.As(err, &statusErr) {
+		t.Errorf("unexpected per call error %v", err)
+	}
+	if message := (&StatusError{StatusCode: http.StatusBadGateway, URL: "http://center"}).Error(); message != "client request http://center fail, 502 Bad Gateway" {
+		t.Errorf("unexpected message %q", message)
+	}
+}
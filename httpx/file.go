@@ -2,19 +2,59 @@ package httpx
 
 import (
 	"context"
+	"crypto/md5"
+	"crypto/sha256"
 	"fmt"
+	"hash"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 )
 
+const (
+	ChecksumMD5    = "md5"
+	ChecksumSHA256 = "sha256"
+)
+
+const (
+	DownloadFileSuffix      = ".download"
+	DownloadValidatorSuffix = ".validator"
+)
+
+type Checksum struct {
+	Algorithm string
+	Value     string
+}
+
+type FileOptions struct {
+	Checksum    *Checksum
+	Resumes     int
+	ChunkSize   int64
+	Concurrency int
+}
+
+var DefaultFileOptions = &FileOptions{
+	Checksum:    nil,
+	Resumes:     3,
+	ChunkSize:   0,
+	Concurrency: 0,
+}
+
 func GetFile(ctx context.Context, save string, path string, query url.Values, headers map[string][]string) error {
 
 	return DefaultClient.GetFile(ctx, save, path, query, headers)
 }
 
+func GetFileWithOptions(ctx context.Context, save string, path string, query url.Values, headers map[string][]string, options *FileOptions) error {
+
+	return DefaultClient.GetFileWithOptions(ctx, save, path, query, headers, options)
+}
+
 func GetFdWith(ctx context.Context, fd *os.File, path string, query url.Values, headers map[string][]string) error {
 
 	return DefaultClient.GetFdWith(ctx, fd, path, query, headers)
@@ -22,22 +62,65 @@ func GetFdWith(ctx context.Context, fd *os.File, path string, query url.Values,
 
 func (client *HttpClient) GetFile(ctx context.Context, save string, path string, query url.Values, headers map[string][]string) error {
 
+	return client.GetFileWithOptions(ctx, save, path, query, headers, DefaultFileOptions)
+}
+
+func (client *HttpClient) GetFileWithOptions(ctx context.Context, save string, path string, query url.Values, headers map[string][]string, options *FileOptions) error {
+
+	if options == nil {
+		options = DefaultFileOptions
+	}
+
 	fpath, err := filepath.Abs(save)
 	if err != nil {
 		return fmt.Errorf("client pull file save path invalid, %s", save)
 	}
 
-	fd, err := os.OpenFile(fpath, os.O_CREATE|os.O_RDWR, 0755)
+	tpath := fpath + DownloadFileSuffix
+	fd, err := os.OpenFile(tpath, os.O_CREATE|os.O_RDWR, 0755)
 	if err != nil {
 		return fmt.Errorf("client pull file open error, %s", err.Error())
 	}
 
-	if err := client.GetFdWith(ctx, fd, path, query, headers); err != nil {
+	if options.ChunkSize > 0 {
+		if err := client.GetFdChunked(ctx, fd, path, query, headers, options.ChunkSize, options.Concurrency); err != nil {
+			fd.Close()
+			return err
+		}
+	} else {
+		validator := &fileValidator{path: tpath + DownloadValidatorSuffix}
+		validator.load()
+		for resumes := 0; ; resumes++ {
+			interrupted, err := client.getFdRange(ctx, fd, path, query, headers, validator)
+			if err == nil {
+				break
+			}
+
+			if !interrupted || resumes >= options.Resumes || ctx.Err() != nil {
+				fd.Close()
+				return err
+			}
+		}
+	}
+
+	if err := fd.Sync(); err != nil {
 		fd.Close()
-		os.Remove(path)
 		return err
 	}
 	fd.Close()
+
+	if options.Checksum != nil {
+		if err := verifyChecksum(tpath, options.Checksum); err != nil {
+			os.Remove(tpath)
+			os.Remove(tpath + DownloadValidatorSuffix)
+			return err
+		}
+	}
+
+	if err := os.Rename(tpath, fpath); err != nil {
+		return err
+	}
+	os.Remove(tpath + DownloadValidatorSuffix)
 	return nil
 }
 
@@ -60,3 +143,194 @@ func (client *HttpClient) GetFdWith(ctx context.Context, fd *os.File, path strin
 	}
 	return fmt.Errorf("client get file fail %d, %s", statusCode, resp.Status())
 }
+
+type fileValidator struct {
+	path  string
+	value string
+}
+
+func (validator *fileValidator) load() {
+
+	if data, err := ioutil.ReadFile(validator.path); err == nil {
+		validator.value = strings.TrimSpace(string(data))
+	}
+}
+
+func (validator *fileValidator) store(resp *HttpResponse) error {
+
+	validator.value = rangeValidator(resp)
+	if validator.value == "" {
+		if err := os.Remove(validator.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return ioutil.WriteFile(validator.path, []byte(validator.value), 0644)
+}
+
+func rangeValidator(resp *HttpResponse) string {
+
+	if etag := resp.Header("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return resp.Header("Last-Modified")
+}
+
+func (client *HttpClient) getFdRange(ctx context.Context, fd *os.File, path string, query url.Values, headers map[string][]string, validator *fileValidator) (bool, error) {
+
+	offset, err := fd.Seek(0, io.SeekEnd)
+	if err != nil {
+		return false, err
+	}
+
+	if offset > 0 && validator.value == "" {
+		if err := truncateFd(fd); err != nil {
+			return false, err
+		}
+		offset = 0
+	}
+
+	rangeHeaders := make(map[string][]string)
+	for key, value := range headers {
+		rangeHeaders[key] = value
+	}
+
+	if offset > 0 {
+		rangeHeaders["Range"] = []string{fmt.Sprintf("bytes=%d-", offset)}
+		rangeHeaders["If-Range"] = []string{validator.value}
+	}
+
+	resp, err := client.Get(WithStatusError(ctx, false), path, query, rangeHeaders)
+	if err != nil {
+		return false, err
+	}
+
+	defer resp.Close()
+	statusCode := resp.StatusCode()
+	switch statusCode {
+	case http.StatusOK:
+		if err := truncateFd(fd); err != nil {
+			return false, err
+		}
+		if err := validator.store(resp); err != nil {
+			return false, err
+		}
+	case http.StatusPartialContent:
+		start, _, _, ok := parseContentRange(resp.Header("Content-Range"))
+		if !ok || start != offset {
+			return false, fmt.Errorf("client get file range invalid, %s", resp.Header("Content-Range"))
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		if offset > 0 {
+			if total, ok := parseUnsatisfiedRange(resp.Header("Content-Range")); ok && total == offset {
+				return false, nil
+			}
+
+			if err := truncateFd(fd); err != nil {
+				return false, err
+			}
+			validator.value = ""
+			return client.getFdRange(ctx, fd, path, query, headers, validator)
+		}
+		fallthrough
+	default:
+		return false, fmt.Errorf("client get file fail %d, %s", statusCode, resp.Status())
+	}
+
+	if _, err := io.Copy(fd, resp.body); err != nil {
+		return true, err
+	}
+	return false, nil
+}
+
+func truncateFd(fd *os.File) error {
+
+	if err := fd.Truncate(0); err != nil {
+		return err
+	}
+
+	_, err := fd.Seek(0, io.SeekStart)
+	return err
+}
+
+func parseUnsatisfiedRange(value string) (int64, bool) {
+
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "bytes */") {
+		return 0, false
+	}
+
+	total, err := strconv.ParseInt(strings.TrimPrefix(value, "bytes */"), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return total, true
+}
+
+func parseContentRange(value string) (int64, int64, int64, bool) {
+
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "bytes ") {
+		return 0, 0, 0, false
+	}
+
+	value = strings.TrimPrefix(value, "bytes ")
+	index := strings.Index(value, "/")
+	if index < 0 {
+		return 0, 0, 0, false
+	}
+
+	total := int64(-1)
+	if size := value[index+1:]; size != "*" {
+		n, err := strconv.ParseInt(size, 10, 64)
+		if err != nil {
+			return 0, 0, 0, false
+		}
+		total = n
+	}
+
+	bounds := strings.SplitN(value[:index], "-", 2)
+	if len(bounds) != 2 {
+		return 0, 0, 0, false
+	}
+
+	start, err := strconv.ParseInt(bounds[0], 10, 64)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+
+	end, err := strconv.ParseInt(bounds[1], 10, 64)
+	if err != nil || end < start {
+		return 0, 0, 0, false
+	}
+	return start, end, total, true
+}
+
+func verifyChecksum(fpath string, checksum *Checksum) error {
+
+	var h hash.Hash
+	switch strings.ToLower(checksum.Algorithm) {
+	case ChecksumMD5:
+		h = md5.New()
+	case ChecksumSHA256:
+		h = sha256.New()
+	default:
+		return fmt.Errorf("client checksum algorithm invalid, %s", checksum.Algorithm)
+	}
+
+	f, err := os.Open(fpath)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+
+	code := fmt.Sprintf("%x", h.Sum(nil))
+	if !strings.EqualFold(code, checksum.Value) {
+		return fmt.Errorf("client checksum mismatch, expected %s, got %s", checksum.Value, code)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetFileResume(t *testing.T) {
+	content := []byte(strings.Repeat("humpback", 4096))
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "artifact", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "httpx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	save := filepath.Join(dir, "artifact")
+	if err := ioutil.WriteFile(save+DownloadFileSuffix, content[:1000], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(save+DownloadFileSuffix+DownloadValidatorSuffix, []byte(`"v1"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	options := &FileOptions{
+		Checksum: &Checksum{Algorithm: ChecksumSHA256, Value: fmt.Sprintf("%x", sha256.Sum256(content))},
+	}
+	if err := NewClient().GetFileWithOptions(context.Background(), save, server.URL, nil, nil, options); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(save)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, content) {
+		t.Error("could not resume file correctly")
+	}
+	if len(ranges) != 1 || ranges[0] != "bytes=1000-" {
+		t.Errorf("unexpected range requests %v", ranges)
+	}
+	if _, err := os.Stat(save + DownloadFileSuffix); !os.IsNotExist(err) {
+		t.Error("temp file not renamed")
+	}
+	if _, err := os.Stat(save + DownloadFileSuffix + DownloadValidatorSuffix); !os.IsNotExist(err) {
+		t.Error("validator file not removed")
+	}
+}
+
+func TestGetFileResumeStale(t *testing.T) {
+	content := []byte("humpback10")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v2"`)
+		http.ServeContent(w, r, "artifact", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "httpx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	save := filepath.Join(dir, "artifact")
+	for _, validator := range []string{"", `"v1"`, `"v2"`} {
+		if err := ioutil.WriteFile(save+DownloadFileSuffix, []byte(strings.Repeat("stale", 5)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		os.Remove(save + DownloadFileSuffix + DownloadValidatorSuffix)
+		if validator != "" {
+			if err := ioutil.WriteFile(save+DownloadFileSuffix+DownloadValidatorSuffix, []byte(validator), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if err := NewClient().GetFile(context.Background(), save, server.URL, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := ioutil.ReadFile(save)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, content) {
+			t.Errorf("validator %q unexpected content %q", validator, data)
+		}
+	}
+}
+
+func TestGetFileChecksumMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("humpback"))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "httpx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	save := filepath.Join(dir, "artifact")
+	options := &FileOptions{
+		Checksum: &Checksum{Algorithm: ChecksumMD5, Value: "00000000000000000000000000000000"},
+	}
+	if err := NewClient().GetFileWithOptions(context.Background(), save, server.URL, nil, nil, options); err == nil {
+		t.Error("checksum mismatch not detected")
+	}
+	if _, err := os.Stat(save); !os.IsNotExist(err) {
+		t.Error("unverified file should not be saved")
+	}
+}
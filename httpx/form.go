@@ -0,0 +1,119 @@
+package httpx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+)
+
+type MultipartFile struct {
+	FieldName   string
+	FileName    string
+	ContentType string
+	Reader      io.Reader
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func PostForm(ctx context.Context, path string, query url.Values, form url.Values, headers map[string][]string) (*HttpResponse, error) {
+
+	return DefaultClient.PostForm(ctx, path, query, form, headers)
+}
+
+func PostMultipart(ctx context.Context, path string, query url.Values, fields url.Values, files []*MultipartFile, headers map[string][]string) (*HttpResponse, error) {
+
+	return DefaultClient.PostMultipart(ctx, path, query, fields, files, headers)
+}
+
+func (client *HttpClient) PostForm(ctx context.Context, path string, query url.Values, form url.Values, headers map[string][]string) (*HttpResponse, error) {
+
+	httpBuffer := client.encodeForm(form, headers)
+	defer client.putBuffer(httpBuffer.Data)
+	return client.sendRequest(ctx, &HttpRequest{
+		Method:  http.MethodPost,
+		RawURL:  path,
+		Query:   query,
+		Data:    httpBuffer.Data,
+		Headers: httpBuffer.Headers,
+	})
+}
+
+func (client *HttpClient) PostMultipart(ctx context.Context, path string, query url.Values, fields url.Values, files []*MultipartFile, headers map[string][]string) (*HttpResponse, error) {
+
+	reader, writer := io.Pipe()
+	defer reader.Close()
+
+	mw := multipart.NewWriter(writer)
+	go func() {
+		writer.CloseWithError(writeMultipart(mw, fields, files))
+	}()
+
+	if headers == nil {
+		headers = make(map[string][]string)
+	}
+
+	headers["Content-Type"] = []string{mw.FormDataContentType()}
+	return client.sendRequest(ctx, &HttpRequest{
+		Method:  http.MethodPost,
+		RawURL:  path,
+		Query:   query,
+		Data:    reader,
+		Headers: headers,
+	})
+}
+
+func (client *HttpClient) encodeForm(form url.Values, headers map[string][]string) *httpBuffer {
+
+	data := client.getBuffer()
+	data.WriteString(form.Encode())
+	if headers == nil {
+		headers = make(map[string][]string)
+	}
+
+	headers["Content-Type"] = []string{"application/x-www-form-urlencoded"}
+	return &httpBuffer{
+		Data:    data,
+		Headers: headers,
+	}
+}
+
+func writeMultipart(mw *multipart.Writer, fields url.Values, files []*MultipartFile) error {
+
+	for key, values := range fields {
+		for _, value := range values {
+			if err := mw.WriteField(key, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, file := range files {
+		if file == nil || file.Reader == nil {
+			continue
+		}
+
+		contentType := file.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			quoteEscaper.Replace(file.FieldName), quoteEscaper.Replace(file.FileName)))
+		header.Set("Content-Type", contentType)
+		part, err := mw.CreatePart(header)
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.Copy(part, file.Reader); err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
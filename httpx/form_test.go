@@ -0,0 +1,107 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type failingReader struct {
+	remain int
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.remain <= 0 {
+		return 0, errors.New("reader broken")
+	}
+	if len(p) > r.remain {
+		p = p[:r.remain]
+	}
+	for i := range p {
+		p[i] = 'x'
+	}
+	r.remain -= len(p)
+	return len(p), nil
+}
+
+func TestPostForm(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+			t.Errorf("unexpected content type %s", r.Header.Get("Content-Type"))
+		}
+		r.ParseForm()
+		w.Write([]byte(r.PostForm.Get("name") + " " + r.PostForm.Get("tag")))
+	}))
+	defer server.Close()
+
+	form := url.Values{"name": {"humpback"}, "tag": {"a&b=c"}}
+	resp, err := NewClient().PostForm(context.Background(), server.URL, nil, form, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+	if body := resp.String(); body != "humpback a&b=c" {
+		t.Errorf("unexpected form body %q", body)
+	}
+}
+
+func TestPostMultipart(t *testing.T) {
+	content := strings.Repeat("humpback", 64<<10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength != -1 {
+			t.Errorf("expected streamed body, content length %d", r.ContentLength)
+		}
+		reader, err := r.MultipartReader()
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		parts := []string{}
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			data, err := ioutil.ReadAll(part)
+			if err != nil || len(data) < 8 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			parts = append(parts, part.FormName()+":"+part.FileName()+":"+part.Header.Get("Content-Type")+":"+string(data[:8]))
+			if part.FileName() != "" && string(data) != content {
+				t.Errorf("unexpected file content size %d", len(data))
+			}
+		}
+		w.Write([]byte(strings.Join(parts, ",")))
+	}))
+	defer server.Close()
+
+	files := []*MultipartFile{
+		{FieldName: "artifact", FileName: "agent.tar", Reader: strings.NewReader(content)},
+		nil,
+	}
+	fields := url.Values{"name": {"humpback"}}
+	resp, err := NewClient().PostMultipart(context.Background(), server.URL, nil, fields, files, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+	if body := resp.String(); body != "name:::humpback,artifact:agent.tar:application/octet-stream:humpback" {
+		t.Errorf("unexpected multipart body %q", body)
+	}
+
+	files = []*MultipartFile{{FieldName: "artifact", FileName: "agent.tar", Reader: &failingReader{remain: 1 << 20}}}
+	if _, err := NewClient().PostMultipart(context.Background(), server.URL, nil, nil, files, nil); err == nil {
+		t.Error("expected multipart writer error")
+	}
+}
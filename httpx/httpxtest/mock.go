@@ -0,0 +1,320 @@
+package httpxtest
+
+import "github.com/humpback/gounits/httpx"
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrUnexpectedRequest = errors.New("mock unexpected request.")
+
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+type Expectation struct {
+	method   string
+	pattern  string
+	query    map[string][]string
+	headers  map[string]string
+	body     interface{}
+	hasBody  bool
+	times    int
+	calls    int
+	delay    time.Duration
+	err      error
+	handler  http.Handler
+	status   int
+	response []byte
+	respond  http.Header
+}
+
+func (expectation *Expectation) WithQuery(key string, value string) *Expectation {
+
+	expectation.query[key] = append(expectation.query[key], value)
+	return expectation
+}
+
+func (expectation *Expectation) WithHeader(key string, value string) *Expectation {
+
+	expectation.headers[key] = value
+	return expectation
+}
+
+func (expectation *Expectation) WithJSON(object interface{}) *Expectation {
+
+	expectation.body = object
+	expectation.hasBody = true
+	return expectation
+}
+
+func (expectation *Expectation) Times(times int) *Expectation {
+
+	expectation.times = times
+	return expectation
+}
+
+func (expectation *Expectation) Delay(delay time.Duration) *Expectation {
+
+	expectation.delay = delay
+	return expectation
+}
+
+func (expectation *Expectation) Fail(err error) *Expectation {
+
+	expectation.err = err
+	return expectation
+}
+
+func (expectation *Expectation) Respond(status int, body string) *Expectation {
+
+	expectation.status = status
+	expectation.response = []byte(body)
+	return expectation
+}
+
+func (expectation *Expectation) RespondJSON(status int, object interface{}) *Expectation {
+
+	data, err := json.Marshal(object)
+	if err != nil {
+		panic(fmt.Sprintf("mock response json invalid, %s", err.Error()))
+	}
+
+	expectation.status = status
+	expectation.response = data
+	expectation.respond.Set("Content-Type", "application/json")
+	return expectation
+}
+
+func (expectation *Expectation) RespondHeader(key string, value string) *Expectation {
+
+	expectation.respond.Add(key, value)
+	return expectation
+}
+
+func (expectation *Expectation) Handle(handler http.HandlerFunc) *Expectation {
+
+	expectation.handler = handler
+	return expectation
+}
+
+func (expectation *Expectation) String() string {
+
+	return expectation.method + " " + expectation.pattern
+}
+
+func (expectation *Expectation) match(request *http.Request, body []byte) bool {
+
+	if expectation.times > 0 && expectation.calls >= expectation.times {
+		return false
+	}
+
+	if expectation.method != "" && expectation.method != request.Method {
+		return false
+	}
+
+	if !matchPath(expectation.pattern, request.URL.Path) {
+		return false
+	}
+
+	query := request.URL.Query()
+	for key, values := range expectation.query {
+		for _, value := range values {
+			if !contains(query[key], value) {
+				return false
+			}
+		}
+	}
+
+	for key, value := range expectation.headers {
+		if request.Header.Get(key) != value {
+			return false
+		}
+	}
+
+	if expectation.hasBody {
+		var expected, actual interface{}
+		data, err := json.Marshal(expectation.body)
+		if err != nil || json.Unmarshal(data, &expected) != nil || json.Unmarshal(body, &actual) != nil {
+			return false
+		}
+		b1, _ := json.Marshal(expected)
+		b2, _ := json.Marshal(actual)
+		return bytes.Equal(b1, b2)
+	}
+	return true
+}
+
+func (expectation *Expectation) serve(request *http.Request) (*http.Response, error) {
+
+	if expectation.delay > 0 {
+		timer := time.NewTimer(expectation.delay)
+		select {
+		case <-request.Context().Done():
+			timer.Stop()
+			return nil, request.Context().Err()
+		case <-timer.C:
+		}
+	}
+
+	if expectation.err != nil {
+		return nil, expectation.err
+	}
+
+	recorder := httptest.NewRecorder()
+	if expectation.handler != nil {
+		expectation.handler.ServeHTTP(recorder, request)
+	} else {
+		for key, values := range expectation.respond {
+			recorder.Header()[key] = values
+		}
+		recorder.WriteHeader(expectation.status)
+		recorder.Write(expectation.response)
+	}
+
+	response := recorder.Result()
+	response.Request = request
+	return response, nil
+}
+
+type MockServer struct {
+	sync.Mutex
+	expectations []*Expectation
+	unexpected   []string
+}
+
+func NewMockServer() *MockServer {
+
+	return &MockServer{
+		expectations: []*Expectation{},
+		unexpected:   []string{},
+	}
+}
+
+func (server *MockServer) Expect(method string, pattern string) *Expectation {
+
+	expectation := &Expectation{
+		method:  method,
+		pattern: pattern,
+		query:   make(map[string][]string),
+		headers: make(map[string]string),
+		status:  http.StatusOK,
+		respond: http.Header{},
+	}
+
+	server.Lock()
+	server.expectations = append(server.expectations, expectation)
+	server.Unlock()
+	return expectation
+}
+
+func (server *MockServer) Client() *httpx.HttpClient {
+
+	return httpx.NewWithClient(&http.Client{Transport: server})
+}
+
+func (server *MockServer) RoundTrip(request *http.Request) (*http.Response, error) {
+
+	request, body, err := readRequestBody(request)
+	if err != nil {
+		return nil, err
+	}
+
+	server.Lock()
+	var matched *Expectation
+	for _, expectation := range server.expectations {
+		if expectation.match(request, body) {
+			matched = expectation
+			matched.calls++
+			break
+		}
+	}
+
+	if matched == nil {
+		server.unexpected = append(server.unexpected, request.Method+" "+request.URL.String())
+	}
+	server.Unlock()
+
+	if matched == nil {
+		return nil, fmt.Errorf("%w, %s %s", ErrUnexpectedRequest, request.Method, request.URL.String())
+	}
+	return matched.serve(request)
+}
+
+func (server *MockServer) Verify() error {
+
+	server.Lock()
+	defer server.Unlock()
+	failures := []string{}
+	for _, expectation := range server.expectations {
+		if expectation.calls == 0 {
+			failures = append(failures, fmt.Sprintf("%s not called", expectation))
+		} else if expectation.times > 0 && expectation.calls != expectation.times {
+			failures = append(failures, fmt.Sprintf("%s called %d of %d times", expectation, expectation.calls, expectation.times))
+		}
+	}
+
+	for _, request := range server.unexpected {
+		failures = append(failures, fmt.Sprintf("%s unexpected", request))
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("mock expectations unmet, %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+func (server *MockServer) AssertExpectations(t TestingT) bool {
+
+	if err := server.Verify(); err != nil {
+		t.Errorf("%s", err.Error())
+		return false
+	}
+	return true
+}
+
+func matchPath(pattern string, path string) bool {
+
+	if pattern == "" || pattern == "*" {
+		return true
+	}
+
+	patterns := strings.Split(strings.Trim(pattern, "/"), "/")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range patterns {
+		if part == "*" && i == len(patterns)-1 {
+			return true
+		}
+
+		if i >= len(segments) {
+			return false
+		}
+
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			continue
+		}
+
+		if part != segments[i] {
+			return false
+		}
+	}
+	return len(patterns) == len(segments)
+}
+
+func contains(values []string, value string) bool {
+
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package httpxtest
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testingT struct {
+	errors []string
+}
+
+func (t *testingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, format)
+}
+
+func TestMockServer(t *testing.T) {
+	server := NewMockServer()
+	server.Expect(http.MethodPost, "/v1/groups/{groupid}/nodes").
+		WithQuery("force", "true").
+		WithJSON(map[string]interface{}{"ip": "192.168.1.10", "port": 8500}).
+		RespondJSON(http.StatusCreated, map[string]string{"id": "node1"}).
+		Times(1)
+	server.Expect(http.MethodGet, "/v1/nodes/*").Fail(errors.New("connection reset"))
+	server.Expect(http.MethodGet, "/v1/slow").Delay(time.Second)
+
+	client := server.Client()
+	resp, err := client.PostJSON(context.Background(), "http://center/v1/groups/g1/nodes", map[string][]string{"force": {"true"}}, map[string]interface{}{"port": 8500, "ip": "192.168.1.10"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := map[string]string{}
+	if err := resp.JSON(&value); err != nil {
+		t.Fatal(err)
+	}
+	resp.Close()
+	if resp.StatusCode() != http.StatusCreated || value["id"] != "node1" {
+		t.Errorf("unexpected response %d %v", resp.StatusCode(), value)
+	}
+
+	if _, err := client.Get(context.Background(), "http://center/v1/nodes/node1/status", nil, nil); err == nil {
+		t.Errorf("expected injected failure")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := client.Get(ctx, "http://center/v1/slow", nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unexpected delay error %v", err)
+	}
+
+	server.AssertExpectations(t)
+	if _, err := client.PostJSON(context.Background(), "http://center/v1/groups/g1/nodes", map[string][]string{"force": {"true"}}, map[string]interface{}{"ip": "192.168.1.10", "port": 8500}, nil); !errors.Is(err, ErrUnexpectedRequest) {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	mock := &testingT{}
+	if server.AssertExpectations(mock) || len(mock.errors) != 1 {
+		t.Errorf("expected unexpected request failure")
+	}
+}
+
+func TestMockServerKeepsRequest(t *testing.T) {
+	server := NewMockServer()
+	server.Expect(http.MethodPost, "/v1/nodes").WithJSON(map[string]string{"ip": "192.168.1.10"})
+
+	body := ioutil.NopCloser(strings.NewReader(`{"ip":"192.168.1.10"}`))
+	request, err := http.NewRequest(http.MethodPost, "http://center/v1/nodes", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := server.RoundTrip(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if request.Body != body {
+		t.Errorf("request body replaced by round tripper")
+	}
+	server.AssertExpectations(t)
+}
@@ -0,0 +1,361 @@
+package httpxtest
+
+import "github.com/humpback/gounits/httpx"
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"unicode/utf8"
+)
+
+type Mode int
+
+const (
+	ModeReplay Mode = iota
+	ModeRecord
+	ModeReplayOrRecord
+)
+
+var ErrInteractionNotFound = errors.New("recorder interaction not found.")
+
+var DefaultRedactHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "Proxy-Authorization"}
+
+type RecordedRequest struct {
+	Method       string      `json:"method"`
+	URL          string      `json:"url"`
+	Header       http.Header `json:"header,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"body_encoding,omitempty"`
+}
+
+type RecordedResponse struct {
+	StatusCode   int         `json:"status_code"`
+	Status       string      `json:"status"`
+	Header       http.Header `json:"header,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"body_encoding,omitempty"`
+}
+
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+func LoadCassette(file string) (*Cassette, error) {
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	cassette := &Cassette{}
+	if err := json.Unmarshal(data, cassette); err != nil {
+		return nil, fmt.Errorf("recorder cassette %s invalid, %s", file, err.Error())
+	}
+	return cassette, nil
+}
+
+func (cassette *Cassette) Save(file string) error {
+
+	data, err := json.MarshalIndent(cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, data, 0644)
+}
+
+type Matcher func(request *http.Request, body []byte, recorded *RecordedRequest) bool
+
+func MatchMethod(request *http.Request, body []byte, recorded *RecordedRequest) bool {
+
+	return request.Method == recorded.Method
+}
+
+func MatchURL(request *http.Request, body []byte, recorded *RecordedRequest) bool {
+
+	return request.URL.String() == recorded.URL
+}
+
+func MatchBody(request *http.Request, body []byte, recorded *RecordedRequest) bool {
+
+	data, err := decodeBody(recorded.Body, recorded.BodyEncoding)
+	if err != nil {
+		return false
+	}
+
+	var v1, v2 interface{}
+	if json.Unmarshal(body, &v1) == nil && json.Unmarshal(data, &v2) == nil {
+		b1, _ := json.Marshal(v1)
+		b2, _ := json.Marshal(v2)
+		return bytes.Equal(b1, b2)
+	}
+	return bytes.Equal(body, data)
+}
+
+func MatchHeaders(keys ...string) Matcher {
+
+	return func(request *http.Request, body []byte, recorded *RecordedRequest) bool {
+		for _, key := range keys {
+			if request.Header.Get(key) != recorded.Header.Get(key) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+var DefaultMatchers = []Matcher{MatchMethod, MatchURL}
+
+type Recorder struct {
+	sync.Mutex
+	file          string
+	mode          Mode
+	cassette      *Cassette
+	transport     http.RoundTripper
+	matchers      []Matcher
+	redactHeaders []string
+	replayed      map[*Interaction]bool
+	changed       bool
+}
+
+func NewRecorder(file string, mode Mode, transport http.RoundTripper) (*Recorder, error) {
+
+	if transport == nil {
+		transport = httpx.DefaultTransport
+	}
+
+	cassette, err := LoadCassette(file)
+	if err != nil {
+		if !os.IsNotExist(err) || mode == ModeReplay {
+			return nil, err
+		}
+		cassette = &Cassette{}
+	}
+
+	if mode == ModeRecord {
+		cassette = &Cassette{}
+	}
+
+	return &Recorder{
+		file:          file,
+		mode:          mode,
+		cassette:      cassette,
+		transport:     transport,
+		matchers:      DefaultMatchers,
+		redactHeaders: DefaultRedactHeaders,
+		replayed:      make(map[*Interaction]bool),
+	}, nil
+}
+
+func (recorder *Recorder) SetMatchers(matchers ...Matcher) *Recorder {
+
+	recorder.Lock()
+	recorder.matchers = matchers
+	recorder.Unlock()
+	return recorder
+}
+
+func (recorder *Recorder) SetRedactHeaders(keys ...string) *Recorder {
+
+	recorder.Lock()
+	recorder.redactHeaders = keys
+	recorder.Unlock()
+	return recorder
+}
+
+func (recorder *Recorder) Client() *httpx.HttpClient {
+
+	return httpx.NewWithClient(&http.Client{Transport: recorder})
+}
+
+func (recorder *Recorder) Cassette() *Cassette {
+
+	return recorder.cassette
+}
+
+func (recorder *Recorder) Stop() error {
+
+	recorder.Lock()
+	defer recorder.Unlock()
+	if recorder.mode == ModeReplay || !recorder.changed {
+		return nil
+	}
+
+	recorder.changed = false
+	return recorder.cassette.Save(recorder.file)
+}
+
+func (recorder *Recorder) RoundTrip(request *http.Request) (*http.Response, error) {
+
+	request, body, err := readRequestBody(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if recorder.mode != ModeRecord {
+		if interaction := recorder.match(request, body); interaction != nil {
+			return interaction.Response.response(request)
+		}
+
+		if recorder.mode == ModeReplay {
+			return nil, fmt.Errorf("%w, %s %s", ErrInteractionNotFound, request.Method, request.URL.String())
+		}
+	}
+	return recorder.record(request, body)
+}
+
+func (recorder *Recorder) match(request *http.Request, body []byte) *Interaction {
+
+	recorder.Lock()
+	defer recorder.Unlock()
+	var matched *Interaction
+	for _, interaction := range recorder.cassette.Interactions {
+		if !recorder.matches(request, body, &interaction.Request) {
+			continue
+		}
+
+		matched = interaction
+		if !recorder.replayed[interaction] {
+			break
+		}
+	}
+
+	if matched != nil {
+		recorder.replayed[matched] = true
+	}
+	return matched
+}
+
+func (recorder *Recorder) matches(request *http.Request, body []byte, recorded *RecordedRequest) bool {
+
+	for _, matcher := range recorder.matchers {
+		if !matcher(request, body, recorded) {
+			return false
+		}
+	}
+	return true
+}
+
+func (recorder *Recorder) record(request *http.Request, body []byte) (*http.Response, error) {
+
+	response, err := recorder.transport.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response.Body = ioutil.NopCloser(bytes.NewReader(data))
+	requestBody, requestEncoding := encodeBody(body)
+	responseBody, responseEncoding := encodeBody(data)
+	interaction := &Interaction{
+		Request: RecordedRequest{
+			Method:       request.Method,
+			URL:          request.URL.String(),
+			Header:       recorder.redact(request.Header),
+			Body:         requestBody,
+			BodyEncoding: requestEncoding,
+		},
+		Response: RecordedResponse{
+			StatusCode:   response.StatusCode,
+			Status:       response.Status,
+			Header:       recorder.redact(response.Header),
+			Body:         responseBody,
+			BodyEncoding: responseEncoding,
+		},
+	}
+
+	recorder.Lock()
+	recorder.cassette.Interactions = append(recorder.cassette.Interactions, interaction)
+	recorder.replayed[interaction] = true
+	recorder.changed = true
+	recorder.Unlock()
+	return response, nil
+}
+
+func (recorder *Recorder) redact(header http.Header) http.Header {
+
+	redacted := header.Clone()
+	for _, key := range recorder.redactHeaders {
+		redacted.Del(key)
+	}
+	return redacted
+}
+
+func (recorded *RecordedResponse) response(request *http.Request) (*http.Response, error) {
+
+	data, err := decodeBody(recorded.Body, recorded.BodyEncoding)
+	if err != nil {
+		return nil, err
+	}
+
+	header := recorded.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        recorded.Status,
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(data)),
+		ContentLength: int64(len(data)),
+		Request:       request,
+	}, nil
+}
+
+func readRequestBody(request *http.Request) (*http.Request, []byte, error) {
+
+	if request.Body == nil || request.Body == http.NoBody {
+		return request, nil, nil
+	}
+
+	data, err := ioutil.ReadAll(request.Body)
+	request.Body.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cloned := request.Clone(request.Context())
+	cloned.Body = ioutil.NopCloser(bytes.NewReader(data))
+	return cloned, data, nil
+}
+
+func encodeBody(data []byte) (string, string) {
+
+	if utf8.Valid(data) {
+		return string(data), ""
+	}
+	return base64.StdEncoding.EncodeToString(data), "base64"
+}
+
+func decodeBody(body string, encoding string) ([]byte, error) {
+
+	if encoding == "base64" {
+		return base64.StdEncoding.DecodeString(body)
+	}
+	return []byte(body), nil
+}
@@ -0,0 +1,65 @@
+package httpxtest
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecorderReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "httpxtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{\"method\":\"" + r.Method + "\",\"body\":" + string(body) + "}"))
+	}))
+
+	file := filepath.Join(dir, "cassette.json")
+	recorder, err := NewRecorder(file, ModeRecord, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder.SetMatchers(MatchMethod, MatchURL, MatchBody)
+	resp, err := recorder.Client().PostJSON(context.Background(), server.URL+"/nodes", nil, map[string]string{"name": "humpback"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded := resp.String()
+	resp.Close()
+	server.Close()
+	if err := recorder.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	replayer, err := NewRecorder(file, ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replayer.SetMatchers(MatchMethod, MatchURL, MatchBody)
+	client := replayer.Client()
+	resp, err = client.PostJSON(context.Background(), server.URL+"/nodes", nil, map[string]string{"name": "humpback"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed := resp.String(); replayed != recorded {
+		t.Errorf("unexpected replay %s, recorded %s", replayed, recorded)
+	}
+	resp.Close()
+
+	_, err = client.PostJSON(context.Background(), server.URL+"/nodes", nil, map[string]string{"name": "center"}, nil)
+	if !errors.Is(err, ErrInteractionNotFound) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
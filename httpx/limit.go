@@ -0,0 +1,228 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+var ErrRateLimit = errors.New("client rate limit wait exceeds context deadline.")
+
+type rateLimiter struct {
+	sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+
+	if rate <= 0 {
+		return nil
+	}
+
+	if burst <= 0 {
+		burst = 1
+	}
+
+	return &rateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+func (limiter *rateLimiter) reserve() time.Duration {
+
+	limiter.Lock()
+	defer limiter.Unlock()
+	now := time.Now()
+	limiter.tokens += now.Sub(limiter.last).Seconds() * limiter.rate
+	if limiter.tokens > limiter.burst {
+		limiter.tokens = limiter.burst
+	}
+
+	limiter.last = now
+	limiter.tokens--
+	if limiter.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-limiter.tokens / limiter.rate * float64(time.Second))
+}
+
+func (limiter *rateLimiter) cancel() {
+
+	limiter.Lock()
+	limiter.tokens++
+	limiter.Unlock()
+}
+
+func (limiter *rateLimiter) wait(ctx context.Context) error {
+
+	wait := limiter.reserve()
+	if wait == 0 {
+		return nil
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+		limiter.cancel()
+		return ErrRateLimit
+	}
+
+	timer := time.NewTimer(wait)
+	select {
+	case <-ctx.Done():
+		timer.Stop()
+		limiter.cancel()
+		return ctx.Err()
+	case <-timer.C:
+	}
+	return nil
+}
+
+type inflightLimiter chan struct{}
+
+func newInflightLimiter(max int) inflightLimiter {
+
+	if max <= 0 {
+		return nil
+	}
+	return make(inflightLimiter, max)
+}
+
+func (limiter inflightLimiter) acquire(ctx context.Context) error {
+
+	select {
+	case limiter <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (limiter inflightLimiter) release() {
+
+	<-limiter
+}
+
+type hostLimits struct {
+	rate     *rateLimiter
+	inflight inflightLimiter
+}
+
+type clientLimits struct {
+	global hostLimits
+	hosts  map[string]hostLimits
+}
+
+type releaseBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (body *releaseBody) Close() error {
+
+	err := body.ReadCloser.Close()
+	body.once.Do(body.release)
+	return err
+}
+
+func (client *HttpClient) SetRateLimit(rate float64, burst int) *HttpClient {
+
+	return client.updateLimits(func(limits *clientLimits) {
+		limits.global.rate = newRateLimiter(rate, burst)
+	})
+}
+
+func (client *HttpClient) SetHostRateLimit(host string, rate float64, burst int) *HttpClient {
+
+	return client.updateLimits(func(limits *clientLimits) {
+		hostLimits := limits.hosts[host]
+		hostLimits.rate = newRateLimiter(rate, burst)
+		limits.hosts[host] = hostLimits
+	})
+}
+
+func (client *HttpClient) SetMaxInFlight(max int) *HttpClient {
+
+	return client.updateLimits(func(limits *clientLimits) {
+		limits.global.inflight = newInflightLimiter(max)
+	})
+}
+
+func (client *HttpClient) SetHostMaxInFlight(host string, max int) *HttpClient {
+
+	return client.updateLimits(func(limits *clientLimits) {
+		hostLimits := limits.hosts[host]
+		hostLimits.inflight = newInflightLimiter(max)
+		limits.hosts[host] = hostLimits
+	})
+}
+
+func (client *HttpClient) updateLimits(fn func(limits *clientLimits)) *HttpClient {
+
+	return client.update(func(config *clientConfig) {
+		limits := &clientLimits{
+			hosts: make(map[string]hostLimits),
+		}
+
+		if config.limits != nil {
+			limits.global = config.limits.global
+			for host, hostLimits := range config.limits.hosts {
+				limits.hosts[host] = hostLimits
+			}
+		}
+
+		fn(limits)
+		config.limits = limits
+	})
+}
+
+func (limits *clientLimits) acquire(ctx context.Context, host string, state *requestState) (func(), error) {
+
+	if limits == nil {
+		return func() {}, nil
+	}
+
+	chain := []hostLimits{limits.global}
+	if hostLimits, ret := limits.hosts[host]; ret {
+		chain = append(chain, hostLimits)
+	}
+
+	start := time.Now()
+	defer func() {
+		state.queued += time.Since(start)
+	}()
+
+	acquired := []inflightLimiter{}
+	release := func() {
+		for _, inflight := range acquired {
+			inflight.release()
+		}
+	}
+
+	for _, hostLimits := range chain {
+		if hostLimits.inflight != nil {
+			if err := hostLimits.inflight.acquire(ctx); err != nil {
+				release()
+				return nil, err
+			}
+			acquired = append(acquired, hostLimits.inflight)
+		}
+	}
+
+	for _, hostLimits := range chain {
+		if hostLimits.rate != nil {
+			if err := hostLimits.rate.wait(ctx); err != nil {
+				release()
+				return nil, err
+			}
+		}
+	}
+	return release, nil
+}
@@ -0,0 +1,130 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestMaxInFlight(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("humpback"))
+	}))
+	defer server.Close()
+
+	client := NewClient().SetMaxInFlight(1)
+	held, err := client.Get(context.Background(), server.URL, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.Get(ctx, server.URL, nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unexpected queued error %v", err)
+	}
+
+	done := make(chan *HttpResponse)
+	go func() {
+		resp, err := client.Get(context.Background(), server.URL, nil, nil)
+		if err != nil {
+			t.Error(err)
+		}
+		done <- resp
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	held.Close()
+	resp := <-done
+	if resp == nil {
+		t.FailNow()
+	}
+	resp.Close()
+	if resp.QueueTime() < 40*time.Millisecond {
+		t.Errorf("unexpected queue time %s", resp.QueueTime())
+	}
+
+	var statusErr *StatusError
+	if _, err := client.Get(WithStatusError(context.Background(), true), server.URL+"/fail", nil, nil); !errors.As(err, &statusErr) {
+		t.Errorf("unexpected status error %v", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err = client.Get(ctx, server.URL, nil, nil)
+	if err != nil {
+		t.Fatalf("slot not released after status error, %v", err)
+	}
+	resp.Close()
+}
+
+func TestHostMaxInFlight(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("humpback"))
+	}))
+	defer server.Close()
+
+	rawurl, _ := url.Parse(server.URL)
+	client := NewClient().SetHostMaxInFlight(rawurl.Host, 1)
+	held, err := client.Get(context.Background(), server.URL, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer held.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.Get(ctx, server.URL, nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unexpected queued error %v", err)
+	}
+
+	resp, err := client.Get(context.Background(), "http://localhost:"+rawurl.Port(), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Close()
+}
+
+func TestRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("humpback"))
+	}))
+	defer server.Close()
+
+	client := NewClient().SetRateLimit(10, 1)
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(context.Background(), server.URL, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Close()
+		if i == 1 && resp.QueueTime() < 50*time.Millisecond {
+			t.Errorf("unexpected queue time %s", resp.QueueTime())
+		}
+	}
+
+	client = NewClient().SetRateLimit(1, 1)
+	resp, err := client.Get(context.Background(), server.URL, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := client.Get(ctx, server.URL, nil, nil); !errors.Is(err, ErrRateLimit) {
+		t.Errorf("unexpected rate limit error %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("rate limit waited %s before failing", elapsed)
+	}
+}
@@ -0,0 +1,33 @@
+package httpx
+
+import (
+	"net/http"
+)
+
+type Handler func(request *http.Request) (*http.Response, error)
+
+type Middleware func(next Handler) Handler
+
+func (client *HttpClient) Use(middlewares ...Middleware) *HttpClient {
+
+	return client.update(func(config *clientConfig) {
+		for _, middleware := range middlewares {
+			if middleware != nil {
+				config.middlewares = append(config.middlewares, middleware)
+			}
+		}
+	})
+}
+
+func (config *clientConfig) handler(c *http.Client) Handler {
+
+	handler := Handler(c.Do)
+	if config.tokens != nil {
+		handler = tokenHandler(config.tokens, handler)
+	}
+
+	for i := len(config.middlewares) - 1; i >= 0; i-- {
+		handler = config.middlewares[i](handler)
+	}
+	return handler
+}
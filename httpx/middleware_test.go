@@ -0,0 +1,55 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMiddlewareChain(t *testing.T) {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&count, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(strings.Join(r.Header.Values("X-Chain"), ",")))
+	}))
+	defer server.Close()
+
+	calls := []string{}
+	middleware := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(request *http.Request) (*http.Response, error) {
+				calls = append(calls, name+">")
+				request = request.Clone(request.Context())
+				request.Header.Add("X-Chain", name)
+				response, err := next(request)
+				calls = append(calls, "<"+name)
+				return response, err
+			}
+		}
+	}
+
+	client := NewClient().
+		SetRetryPolicy(&RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond}).
+		Use(middleware("outer"), nil).
+		Use(middleware("inner"))
+	resp, err := client.Get(context.Background(), server.URL, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+
+	if body := resp.String(); body != "outer,inner" {
+		t.Errorf("unexpected chain header %q", body)
+	}
+	expected := strings.Repeat("outer> inner> <inner <outer ", 3)
+	if strings.Join(calls, " ")+" " != expected || resp.Attempts() != 3 {
+		t.Errorf("unexpected calls %v after %d attempts", calls, resp.Attempts())
+	}
+}
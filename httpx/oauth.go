@@ -0,0 +1,291 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultTokenRefreshEarly   = 30 * time.Second
+	DefaultTokenRefreshTimeout = 30 * time.Second
+)
+
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresIn    int64     `json:"expires_in"`
+	Expiry       time.Time `json:"-"`
+}
+
+func (token *Token) Type() string {
+
+	if token.TokenType == "" || strings.EqualFold(token.TokenType, "bearer") {
+		return "Bearer"
+	}
+	return token.TokenType
+}
+
+func (token *Token) valid(early time.Duration) bool {
+
+	if token == nil || token.AccessToken == "" {
+		return false
+	}
+
+	if token.Expiry.IsZero() {
+		return true
+	}
+	return time.Now().Add(early).Before(token.Expiry)
+}
+
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+type staticTokenSource struct {
+	token *Token
+}
+
+func StaticTokenSource(accessToken string) TokenSource {
+
+	return &staticTokenSource{
+		token: &Token{AccessToken: accessToken, TokenType: "Bearer"},
+	}
+}
+
+func (source *staticTokenSource) Token(ctx context.Context) (*Token, error) {
+
+	return source.token, nil
+}
+
+type ClientCredentials struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	Client       *HttpClient
+}
+
+func (credentials *ClientCredentials) Token(ctx context.Context) (*Token, error) {
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	if len(credentials.Scopes) > 0 {
+		form.Set("scope", strings.Join(credentials.Scopes, " "))
+	}
+	return requestToken(ctx, credentials.Client, credentials.TokenURL, credentials.ClientID, credentials.ClientSecret, form)
+}
+
+type RefreshTokenSource struct {
+	sync.Mutex
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	RefreshToken string
+	Client       *HttpClient
+}
+
+func (source *RefreshTokenSource) Token(ctx context.Context) (*Token, error) {
+
+	source.Lock()
+	defer source.Unlock()
+	if source.RefreshToken == "" {
+		return nil, errors.New("client refresh token invalid.")
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("refresh_token", source.RefreshToken)
+	token, err := requestToken(ctx, source.Client, source.TokenURL, source.ClientID, source.ClientSecret, form)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.RefreshToken != "" {
+		source.RefreshToken = token.RefreshToken
+	}
+	return token, nil
+}
+
+func requestToken(ctx context.Context, client *HttpClient, tokenURL string, clientID string, clientSecret string, form url.Values) (*Token, error) {
+
+	if client == nil {
+		client = DefaultClient
+	}
+
+	headers := map[string][]string{
+		"Accept": {"application/json"},
+	}
+
+	if clientID != "" {
+		request := &http.Request{Header: http.Header{}}
+		request.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+		headers["Authorization"] = request.Header["Authorization"]
+	}
+
+	resp, err := client.PostForm(WithStatusError(ctx, true), tokenURL, nil, form, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Close()
+	token := &Token{}
+	if err := resp.JSON(token); err != nil {
+		return nil, err
+	}
+
+	if token.AccessToken == "" {
+		return nil, errors.New("client token response invalid, access_token missing.")
+	}
+
+	if token.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+type tokenCall struct {
+	done  chan struct{}
+	token *Token
+	err   error
+}
+
+type ReuseTokenSource struct {
+	sync.Mutex
+	source TokenSource
+	early  time.Duration
+	token  *Token
+	call   *tokenCall
+}
+
+func NewReuseTokenSource(source TokenSource, early time.Duration) *ReuseTokenSource {
+
+	if reuse, ok := source.(*ReuseTokenSource); ok {
+		return reuse
+	}
+
+	if early < 0 {
+		early = DefaultTokenRefreshEarly
+	}
+
+	return &ReuseTokenSource{
+		source: source,
+		early:  early,
+	}
+}
+
+func (reuse *ReuseTokenSource) Token(ctx context.Context) (*Token, error) {
+
+	reuse.Lock()
+	if reuse.token.valid(reuse.early) {
+		token := reuse.token
+		reuse.Unlock()
+		return token, nil
+	}
+
+	call := reuse.call
+	if call == nil {
+		call = &tokenCall{done: make(chan struct{})}
+		reuse.call = call
+		go reuse.refresh(call)
+	}
+	reuse.Unlock()
+
+	select {
+	case <-call.done:
+		return call.token, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (reuse *ReuseTokenSource) refresh(call *tokenCall) {
+
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), tokenRequestKey, true), DefaultTokenRefreshTimeout)
+	call.token, call.err = reuse.source.Token(ctx)
+	cancel()
+	reuse.Lock()
+	if call.err == nil {
+		reuse.token = call.token
+	}
+	reuse.call = nil
+	reuse.Unlock()
+	close(call.done)
+}
+
+func (reuse *ReuseTokenSource) Invalidate(token *Token) {
+
+	reuse.Lock()
+	if token == nil || reuse.token == token {
+		reuse.token = nil
+	}
+	reuse.Unlock()
+}
+
+func (client *HttpClient) SetTokenSource(source TokenSource) *HttpClient {
+
+	var tokens *ReuseTokenSource
+	if source != nil {
+		tokens = NewReuseTokenSource(source, DefaultTokenRefreshEarly)
+	}
+
+	return client.update(func(config *clientConfig) {
+		config.tokens = tokens
+	})
+}
+
+func tokenHandler(tokens *ReuseTokenSource, next Handler) Handler {
+
+	return func(request *http.Request) (*http.Response, error) {
+		if refreshing, _ := request.Context().Value(tokenRequestKey).(bool); refreshing {
+			return next(request)
+		}
+
+		token, err := tokens.Token(request.Context())
+		if err != nil {
+			return nil, err
+		}
+
+		response, err := next(authorize(request, token))
+		if err != nil || response.StatusCode != http.StatusUnauthorized {
+			return response, err
+		}
+
+		if request.Body != nil && request.Body != http.NoBody && request.GetBody == nil {
+			return response, nil
+		}
+
+		tokens.Invalidate(token)
+		refreshed, err := tokens.Token(request.Context())
+		if err != nil {
+			return response, nil
+		}
+
+		retry := authorize(request, refreshed)
+		if request.GetBody != nil {
+			body, err := request.GetBody()
+			if err != nil {
+				return response, nil
+			}
+			retry.Body = body
+		}
+
+		io.Copy(ioutil.Discard, response.Body)
+		response.Body.Close()
+		return next(retry)
+	}
+}
+
+func authorize(request *http.Request, token *Token) *http.Request {
+
+	authorized := request.Clone(request.Context())
+	authorized.Header.Set("Authorization", token.Type()+" "+token.AccessToken)
+	return authorized
+}
@@ -0,0 +1,118 @@
+package httpx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingTokenSource struct {
+	calls  int32
+	delay  time.Duration
+	expiry time.Duration
+}
+
+func (source *countingTokenSource) Token(ctx context.Context) (*Token, error) {
+	calls := atomic.AddInt32(&source.calls, 1)
+	time.Sleep(source.delay)
+	token := &Token{AccessToken: fmt.Sprintf("t%d", calls)}
+	if source.expiry > 0 {
+		token.Expiry = time.Now().Add(source.expiry)
+	}
+	return token, nil
+}
+
+func TestTokenRequestThroughSameClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "humpback", "expires_in": 3600})
+			return
+		}
+		w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.SetTokenSource(&ClientCredentials{TokenURL: server.URL + "/token", ClientID: "agent", Client: client})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := client.Get(ctx, server.URL+"/nodes", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+	if body := resp.String(); body != "Bearer humpback" {
+		t.Errorf("unexpected authorization %q", body)
+	}
+}
+
+func TestReuseTokenSourceSingleFlight(t *testing.T) {
+	source := &countingTokenSource{delay: 50 * time.Millisecond}
+	reuse := NewReuseTokenSource(source, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if token, err := reuse.Token(context.Background()); err != nil || token.AccessToken != "t1" {
+				t.Errorf("unexpected token %v %v", token, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls := atomic.LoadInt32(&source.calls); calls != 1 {
+		t.Errorf("unexpected refresh count %d", calls)
+	}
+}
+
+func TestReuseTokenSourceEarlyRefresh(t *testing.T) {
+	source := &countingTokenSource{expiry: 10 * time.Second}
+	reuse := NewReuseTokenSource(source, 30*time.Second)
+	for i := 1; i <= 2; i++ {
+		if token, err := reuse.Token(context.Background()); err != nil || token.AccessToken != fmt.Sprintf("t%d", i) {
+			t.Errorf("unexpected token %v %v", token, err)
+		}
+	}
+
+	source = &countingTokenSource{expiry: time.Minute}
+	reuse = NewReuseTokenSource(source, 30*time.Second)
+	for i := 0; i < 2; i++ {
+		if token, err := reuse.Token(context.Background()); err != nil || token.AccessToken != "t1" {
+			t.Errorf("unexpected token %v %v", token, err)
+		}
+	}
+}
+
+func TestTokenUnauthorizedRetry(t *testing.T) {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		if r.Header.Get("Authorization") != "Bearer t2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("humpback"))
+	}))
+	defer server.Close()
+
+	source := &countingTokenSource{}
+	client := NewClient().SetTokenSource(source)
+	resp, err := client.PostJSON(context.Background(), server.URL, nil, map[string]string{"name": "humpback"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+	if resp.StatusCode() != http.StatusOK || resp.String() != "humpback" {
+		t.Errorf("unexpected response %d", resp.StatusCode())
+	}
+	if atomic.LoadInt32(&count) != 2 || atomic.LoadInt32(&source.calls) != 2 {
+		t.Errorf("unexpected requests %d refreshes %d", count, source.calls)
+	}
+}
@@ -0,0 +1,215 @@
+package httpx
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Params map[string]interface{}
+
+func (client *HttpClient) SetBaseURL(rawurl string) *HttpClient {
+
+	return client.update(func(config *clientConfig) {
+		config.baseurl = rawurl
+	})
+}
+
+func (client *HttpClient) BaseURL() string {
+
+	return client.load().baseurl
+}
+
+func (config *clientConfig) resolveURL(rawurl *url.URL) (*url.URL, error) {
+
+	if config.baseurl == "" || config.endpoints != nil || rawurl.Scheme != "" || rawurl.Host != "" {
+		return rawurl, nil
+	}
+	return joinURL(config.baseurl, rawurl)
+}
+
+func joinURL(base string, rawurl *url.URL) (*url.URL, error) {
+
+	baseurl, err := url.Parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("client base url invalid, %s", err.Error())
+	}
+
+	path := strings.TrimSuffix(baseurl.EscapedPath(), "/")
+	if ref := rawurl.EscapedPath(); ref != "" {
+		path = path + "/" + strings.TrimPrefix(ref, "/")
+	}
+
+	resolved := &url.URL{
+		Scheme: baseurl.Scheme,
+		User:   baseurl.User,
+		Host:   baseurl.Host,
+	}
+	return resolved.Parse(path + queryString(baseurl.RawQuery, rawurl.RawQuery))
+}
+
+func queryString(queries ...string) string {
+
+	values := []string{}
+	for _, query := range queries {
+		if query != "" {
+			values = append(values, query)
+		}
+	}
+
+	if len(values) == 0 {
+		return ""
+	}
+	return "?" + strings.Join(values, "&")
+}
+
+func ExpandPath(template string, params Params) (string, error) {
+
+	var path strings.Builder
+	for {
+		start := strings.Index(template, "{")
+		if start < 0 {
+			path.WriteString(template)
+			return path.String(), nil
+		}
+
+		end := strings.Index(template[start:], "}")
+		if end < 0 {
+			return "", fmt.Errorf("client path template invalid, %s", template)
+		}
+
+		name := template[start+1 : start+end]
+		value, ret := params[name]
+		if !ret {
+			return "", fmt.Errorf("client path param missing, %s", name)
+		}
+
+		path.WriteString(template[:start])
+		path.WriteString(url.PathEscape(fmt.Sprint(value)))
+		template = template[start+end+1:]
+	}
+}
+
+func QueryOf(object interface{}) (url.Values, error) {
+
+	values := url.Values{}
+	if object == nil {
+		return values, nil
+	}
+
+	value := reflect.ValueOf(object)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return values, nil
+		}
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("client query object invalid, %s", value.Kind())
+	}
+
+	if err := encodeQuery(values, value); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func encodeQuery(values url.Values, value reflect.Value) error {
+
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		tag := field.Tag.Get("query")
+		if tag == "-" {
+			continue
+		}
+
+		name, omitempty := field.Name, false
+		if tag != "" {
+			options := strings.Split(tag, ",")
+			if options[0] != "" {
+				name = options[0]
+			}
+			for _, option := range options[1:] {
+				if option == "omitempty" {
+					omitempty = true
+				}
+			}
+		}
+
+		fieldValue := value.Field(i)
+		for fieldValue.Kind() == reflect.Ptr {
+			if fieldValue.IsNil() {
+				break
+			}
+			fieldValue = fieldValue.Elem()
+		}
+
+		if fieldValue.Kind() == reflect.Ptr {
+			continue
+		}
+
+		if field.Anonymous && tag == "" && fieldValue.Kind() == reflect.Struct {
+			if err := encodeQuery(values, fieldValue); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if omitempty && fieldValue.IsZero() {
+			continue
+		}
+
+		if fieldValue.Kind() == reflect.Slice || fieldValue.Kind() == reflect.Array {
+			for j := 0; j < fieldValue.Len(); j++ {
+				s, err := queryValue(fieldValue.Index(j))
+				if err != nil {
+					return fmt.Errorf("client query field %s invalid, %s", field.Name, err.Error())
+				}
+				values.Add(name, s)
+			}
+			continue
+		}
+
+		s, err := queryValue(fieldValue)
+		if err != nil {
+			return fmt.Errorf("client query field %s invalid, %s", field.Name, err.Error())
+		}
+		values.Add(name, s)
+	}
+	return nil
+}
+
+func queryValue(value reflect.Value) (string, error) {
+
+	if value.CanInterface() {
+		switch v := value.Interface().(type) {
+		case time.Time:
+			return v.Format(time.RFC3339), nil
+		case fmt.Stringer:
+			return v.String(), nil
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, 64), nil
+	}
+	return "", fmt.Errorf("unsupported kind %s", value.Kind())
+}
@@ -0,0 +1,132 @@
+package httpx
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type Progress struct {
+	Done  int64
+	Total int64
+	Rate  float64
+}
+
+type ProgressFunc func(progress Progress)
+
+func WithUploadProgress(ctx context.Context, fn ProgressFunc) context.Context {
+
+	return context.WithValue(ctx, uploadProgressKey, fn)
+}
+
+func WithDownloadProgress(ctx context.Context, fn ProgressFunc) context.Context {
+
+	return context.WithValue(ctx, downloadProgressKey, fn)
+}
+
+func WithProgress(ctx context.Context, upload ProgressFunc, download ProgressFunc) context.Context {
+
+	return WithDownloadProgress(WithUploadProgress(ctx, upload), download)
+}
+
+func progressFunc(ctx context.Context, key contextKey) ProgressFunc {
+
+	if fn, ok := ctx.Value(key).(ProgressFunc); ok {
+		return fn
+	}
+	return nil
+}
+
+type progressTracker struct {
+	sync.Mutex
+	fn    ProgressFunc
+	done  int64
+	total int64
+	start time.Time
+}
+
+func newProgressTracker(fn ProgressFunc, total int64) *progressTracker {
+
+	if total < 0 {
+		total = -1
+	}
+
+	return &progressTracker{
+		fn:    fn,
+		done:  0,
+		total: total,
+		start: time.Now(),
+	}
+}
+
+func (tracker *progressTracker) add(n int64) {
+
+	tracker.Lock()
+	defer tracker.Unlock()
+	tracker.done += n
+	rate := float64(0)
+	if elapsed := time.Since(tracker.start).Seconds(); elapsed > 0 {
+		rate = float64(tracker.done) / elapsed
+	}
+
+	tracker.fn(Progress{
+		Done:  tracker.done,
+		Total: tracker.total,
+		Rate:  rate,
+	})
+}
+
+type progressReader struct {
+	io.ReadCloser
+	tracker *progressTracker
+}
+
+func (reader *progressReader) Read(p []byte) (int, error) {
+
+	n, err := reader.ReadCloser.Read(p)
+	if n > 0 || err == io.EOF {
+		reader.tracker.add(int64(n))
+	}
+	return n, err
+}
+
+func withRequestProgress(ctx context.Context, request *http.Request) {
+
+	fn := progressFunc(ctx, uploadProgressKey)
+	if fn == nil || request.Body == nil || request.Body == http.NoBody {
+		return
+	}
+
+	request.Body = &progressReader{
+		ReadCloser: request.Body,
+		tracker:    newProgressTracker(fn, request.ContentLength),
+	}
+
+	if getBody := request.GetBody; getBody != nil {
+		request.GetBody = func() (io.ReadCloser, error) {
+			body, err := getBody()
+			if err != nil {
+				return nil, err
+			}
+			return &progressReader{
+				ReadCloser: body,
+				tracker:    newProgressTracker(fn, request.ContentLength),
+			}, nil
+		}
+	}
+}
+
+func withResponseProgress(ctx context.Context, response *http.Response) {
+
+	fn := progressFunc(ctx, downloadProgressKey)
+	if fn == nil {
+		return
+	}
+
+	response.Body = &progressReader{
+		ReadCloser: response.Body,
+		tracker:    newProgressTracker(fn, response.ContentLength),
+	}
+}
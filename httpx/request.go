@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"time"
 )
 
 type HttpRequest struct {
@@ -17,6 +18,14 @@ type HttpRequest struct {
 	Headers map[string][]string
 }
 
+type requestState struct {
+	config   *clientConfig
+	attempts int
+	cachehit bool
+	queued   time.Duration
+	resolved *endpointPool
+}
+
 func (client *HttpClient) sendRequest(ctx context.Context, req *HttpRequest) (*HttpResponse, error) {
 
 	if req == nil {
@@ -28,7 +37,8 @@ func (client *HttpClient) sendRequest(ctx context.Context, req *HttpRequest) (*H
 		req.Data = bytes.NewReader([]byte{})
 	}
 
-	request, err := client.newRequest(req)
+	config := client.load()
+	request, err := config.newRequest(req)
 	if err != nil {
 		return nil, err
 	}
@@ -37,39 +47,133 @@ func (client *HttpClient) sendRequest(ctx context.Context, req *HttpRequest) (*H
 		request.Header.Set("Content-Type", "text/plain")
 	}
 
-	response, err := client.c.Do(request.WithContext(ctx))
+	config.acceptEncoding(request)
+	withRequestProgress(ctx, request)
+	state := &requestState{config: config}
+	ctx, trace := withRequestTrace(ctx)
+	response, err := client.doCache(ctx, request, state)
+	if err != nil {
+		config.emitTrace(ctx, request, trace.done(), err)
+		return nil, err
+	}
+
+	withResponseProgress(ctx, response)
+	config.decodeResponse(response)
+
+	rawurl := request.URL.String()
+	if request.URL.Scheme == "" && request.URL.Host == "" && response.Request != nil {
+		rawurl = response.Request.URL.String()
+	}
+
+	resp := &HttpResponse{
+		rawurl:     rawurl,
+		body:       response.Body,
+		header:     response.Header,
+		status:     response.Status,
+		statuscode: response.StatusCode,
+		attempts:   state.attempts,
+		cachehit:   state.cachehit,
+		queued:     state.queued,
+		timings:    trace.done(),
+		limit:      config.maxBodySize(ctx),
+	}
+
+	if config.statusError(ctx) && (resp.statuscode < 200 || resp.statuscode > 299) {
+		err := newStatusError(resp)
+		config.emitTrace(ctx, request, resp.timings, err)
+		return nil, err
+	}
+
+	config.emitTrace(ctx, request, resp.timings, nil)
+	return resp, nil
+}
+
+func (client *HttpClient) do(ctx context.Context, request *http.Request, state *requestState) (*http.Response, error) {
+
+	c := state.config.c
+	if state.resolved != nil {
+		c = state.resolved.client(c)
+	}
+
+	if jar := state.config.jar; jar != nil {
+		jarClient := *c
+		jarClient.Jar = jar
+		c = &jarClient
+	}
+
+	host, rawurl := unixHost(request.URL), request.URL.String()
+	if request.URL.Scheme == UnixScheme {
+		unixRequest, unixClient, err := unixRequest(request, c)
+		if err != nil {
+			return nil, err
+		}
+		request, c = unixRequest, unixClient
+	}
+
+	breakers := state.config.breakers
+	if breakers != nil {
+		if err := breakers.allow(host); err != nil {
+			return nil, err
+		}
+	}
+
+	release, err := state.config.limits.acquire(ctx, host, state)
+	if err != nil {
+		if breakers != nil {
+			breakers.release(host)
+		}
+		return nil, err
+	}
+
+	response, err := state.config.handler(c)(request.WithContext(ctx))
 	if err != nil {
 		select {
 		case <-ctx.Done():
 			err = ctx.Err()
 		default:
 		}
+
+		if urlErr, ok := err.(*url.Error); ok && request.URL.String() != rawurl {
+			urlErr.URL = rawurl
+		}
+	}
+
+	if breakers != nil {
+		if ctx.Err() != nil {
+			breakers.release(host)
+		} else {
+			breakers.record(host, breakerFailed(response, err))
+		}
+	}
+
+	if err != nil {
+		release()
 		return nil, err
 	}
 
-	return &HttpResponse{
-		rawurl:     request.URL.String(),
-		body:       response.Body,
-		header:     response.Header,
-		status:     response.Status,
-		statuscode: response.StatusCode,
-	}, nil
+	response.Body = &releaseBody{
+		ReadCloser: response.Body,
+		release:    release,
+	}
+	return response, nil
 }
 
-func (client *HttpClient) newRequest(req *HttpRequest) (*http.Request, error) {
+func (config *clientConfig) newRequest(req *HttpRequest) (*http.Request, error) {
 
 	rawurl, err := url.Parse(req.RawURL)
 	if err != nil {
 		return nil, err
 	}
 
-	q := url.Values{}
-	for key := range rawurl.Query() {
-		q.Add(key, rawurl.Query().Get(key))
+	if rawurl, err = config.resolveURL(rawurl); err != nil {
+		return nil, err
 	}
 
-	for key := range req.Query {
-		q.Add(key, req.Query.Get(key))
+	q := rawurl.Query()
+	for key, values := range req.Query {
+		for _, value := range values {
+			q.Add(key, value)
+		}
 	}
 
 	if len(q) > 0 {
@@ -81,7 +185,7 @@ func (client *HttpClient) newRequest(req *HttpRequest) (*http.Request, error) {
 		return nil, err
 	}
 
-	for key, value := range client.headers {
+	for key, value := range config.headers {
 		request.Header.Set(key, value)
 	}
 
@@ -91,14 +195,14 @@ func (client *HttpClient) newRequest(req *HttpRequest) (*http.Request, error) {
 		}
 	}
 
-	if len(client.cookies) != 0 {
-		for _, cookie := range client.cookies {
+	if len(config.cookies) != 0 {
+		for _, cookie := range config.cookies {
 			request.AddCookie(cookie)
 		}
 	}
 
-	if client.auth.UserName != "" && client.auth.Password != "" {
-		request.SetBasicAuth(client.auth.UserName, client.auth.Password)
+	if config.auth.UserName != "" && config.auth.Password != "" {
+		request.SetBasicAuth(config.auth.UserName, config.auth.Password)
 	}
 	return request, nil
 }
@@ -1,11 +1,15 @@
 package httpx
 
+import "gopkg.in/yaml.v2"
+
 import (
 	"encoding/json"
 	"encoding/xml"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 const ResponseBodyAllSize int64 = 0
@@ -16,6 +20,11 @@ type HttpResponse struct {
 	header     http.Header
 	status     string
 	statuscode int
+	attempts   int
+	cachehit   bool
+	queued     time.Duration
+	timings    Timings
+	limit      int64
 }
 
 func (resp *HttpResponse) Body() io.ReadCloser {
@@ -25,7 +34,19 @@ func (resp *HttpResponse) Body() io.ReadCloser {
 
 func (resp *HttpResponse) Bytes() ([]byte, error) {
 
-	return ioutil.ReadAll(resp.body)
+	return readBody(resp.body, resp.contentLength(), resp.limit)
+}
+
+func (resp *HttpResponse) LimitedBytes(n int64) ([]byte, error) {
+
+	if n <= ResponseBodyAllSize {
+		return resp.Bytes()
+	}
+
+	if resp.limit > ResponseBodyAllSize && n > resp.limit {
+		n = resp.limit
+	}
+	return ioutil.ReadAll(io.LimitReader(resp.body, n))
 }
 
 func (resp *HttpResponse) String() string {
@@ -55,9 +76,18 @@ func (resp *HttpResponse) XML(object interface{}) error {
 	return xml.Unmarshal(buf, object)
 }
 
+func (resp *HttpResponse) YAML(object interface{}) error {
+
+	buf, err := resp.Bytes()
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(buf, object)
+}
+
 func (resp *HttpResponse) JSONMapper(data interface{}) error {
 
-	dec := json.NewDecoder(resp.body)
+	dec := json.NewDecoder(newLimitedReader(resp.body, resp.limit))
 	for {
 		if err := dec.Decode(data); err != nil {
 			if err == io.EOF {
@@ -71,7 +101,7 @@ func (resp *HttpResponse) JSONMapper(data interface{}) error {
 
 func (resp *HttpResponse) XMLMapper(data interface{}) error {
 
-	dec := xml.NewDecoder(resp.body)
+	dec := xml.NewDecoder(newLimitedReader(resp.body, resp.limit))
 	for {
 		if err := dec.Decode(data); err != nil {
 			if err == io.EOF {
@@ -88,6 +118,15 @@ func (resp *HttpResponse) RawURL() string {
 	return resp.rawurl
 }
 
+func (resp *HttpResponse) contentLength() int64 {
+
+	length, err := strconv.ParseInt(resp.header.Get("Content-Length"), 10, 64)
+	if err != nil {
+		return -1
+	}
+	return length
+}
+
 func (resp *HttpResponse) Header(key string) string {
 
 	return resp.header.Get(key)
@@ -108,8 +147,32 @@ func (resp *HttpResponse) StatusCode() int {
 	return resp.statuscode
 }
 
+func (resp *HttpResponse) Attempts() int {
+
+	return resp.attempts
+}
+
+func (resp *HttpResponse) QueueTime() time.Duration {
+
+	return resp.queued
+}
+
+func (resp *HttpResponse) Timings() Timings {
+
+	return resp.timings
+}
+
+func (resp *HttpResponse) CacheHit() bool {
+
+	return resp.cachehit
+}
+
 func (resp *HttpResponse) Close() error {
 
-	io.Copy(ioutil.Discard, resp.body)
+	if resp.limit > ResponseBodyAllSize {
+		io.CopyN(ioutil.Discard, resp.body, resp.limit)
+	} else {
+		io.Copy(ioutil.Discard, resp.body)
+	}
 	return resp.body.Close()
 }
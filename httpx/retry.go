@@ -0,0 +1,160 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type RetryPolicy struct {
+	MaxAttempts int
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+	Jitter      float64
+	RetryOn     func(statuscode int) bool
+}
+
+var DefaultRetryPolicy = &RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  200 * time.Millisecond,
+	MaxBackoff:  10 * time.Second,
+	Jitter:      0.2,
+	RetryOn:     RetryOnServerError,
+}
+
+func RetryOnServerError(statuscode int) bool {
+
+	if statuscode == http.StatusTooManyRequests {
+		return true
+	}
+	return statuscode >= http.StatusInternalServerError && statuscode != http.StatusNotImplemented
+}
+
+func (client *HttpClient) SetRetryPolicy(policy *RetryPolicy) *HttpClient {
+
+	return client.update(func(config *clientConfig) {
+		config.retry = policy
+	})
+}
+
+func (policy *RetryPolicy) retryable(ctx context.Context, response *http.Response, err error) bool {
+
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		return !errors.Is(err, ErrBreakerOpen)
+	}
+
+	retryOn := policy.RetryOn
+	if retryOn == nil {
+		retryOn = RetryOnServerError
+	}
+	return retryOn(response.StatusCode)
+}
+
+func (policy *RetryPolicy) backoff(attempt int, response *http.Response) (time.Duration, bool) {
+
+	if response != nil {
+		if wait, ok := parseRetryAfter(response.Header.Get("Retry-After")); ok {
+			if policy.MaxBackoff > 0 && wait > policy.MaxBackoff {
+				return 0, false
+			}
+			return wait, true
+		}
+	}
+
+	wait := float64(policy.MinBackoff) * math.Pow(2, float64(attempt-1))
+	if policy.MaxBackoff > 0 && wait > float64(policy.MaxBackoff) {
+		wait = float64(policy.MaxBackoff)
+	}
+
+	if policy.Jitter > 0 {
+		wait += wait * policy.Jitter * (rand.Float64()*2 - 1)
+	}
+
+	if wait < 0 {
+		wait = 0
+	}
+	return time.Duration(wait), true
+}
+
+func parseRetryAfter(value string) (time.Duration, bool) {
+
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+func (client *HttpClient) doRetry(ctx context.Context, request *http.Request, state *requestState) (*http.Response, error) {
+
+	policy := state.config.retry
+	if policy == nil || policy.MaxAttempts <= 1 {
+		state.attempts = 1
+		return client.do(ctx, request, state)
+	}
+
+	rewindable := request.Body == nil || request.Body == http.NoBody || request.GetBody != nil
+	for attempt := 1; ; attempt++ {
+		state.attempts = attempt
+		attemptRequest := request
+		if attempt > 1 {
+			attemptRequest = request.Clone(ctx)
+			if request.GetBody != nil {
+				body, err := request.GetBody()
+				if err != nil {
+					state.attempts = attempt - 1
+					return nil, err
+				}
+				attemptRequest.Body = body
+			}
+		}
+
+		response, err := client.do(ctx, attemptRequest, state)
+		if attempt >= policy.MaxAttempts || !rewindable || !policy.retryable(ctx, response, err) {
+			return response, err
+		}
+
+		wait, ok := policy.backoff(attempt, response)
+		if !ok {
+			return response, err
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			return response, err
+		}
+
+		if response != nil {
+			io.Copy(ioutil.Discard, response.Body)
+			response.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package httpx
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryPostJSON(t *testing.T) {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) != "{\"name\":\"humpback\"}\n" {
+			t.Errorf("unexpected body %q", body)
+		}
+		if atomic.AddInt32(&count, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient().SetRetryPolicy(&RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  10 * time.Millisecond,
+	})
+	resp, err := client.PostJSON(context.Background(), server.URL, nil, map[string]string{"name": "humpback"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+	if resp.StatusCode() != http.StatusOK {
+		t.Errorf("unexpected status %d", resp.StatusCode())
+	}
+	if resp.Attempts() != 3 {
+		t.Errorf("unexpected attempts %d", resp.Attempts())
+	}
+}
+
+func TestRetryAfterDeadline(t *testing.T) {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	client := NewClient().SetRetryPolicy(DefaultRetryPolicy)
+	resp, err := client.Get(ctx, server.URL, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+	if resp.StatusCode() != http.StatusTooManyRequests || resp.Attempts() != 1 {
+		t.Errorf("unexpected result %d after %d attempts", resp.StatusCode(), resp.Attempts())
+	}
+	if atomic.LoadInt32(&count) != 1 {
+		t.Errorf("unexpected request count %d", count)
+	}
+}
+
+func TestRetryAfterMaxBackoff(t *testing.T) {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient().SetRetryPolicy(DefaultRetryPolicy)
+	resp, err := client.Get(context.Background(), server.URL, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+	if resp.StatusCode() != http.StatusServiceUnavailable || resp.Attempts() != 1 {
+		t.Errorf("unexpected result %d after %d attempts", resp.StatusCode(), resp.Attempts())
+	}
+	if atomic.LoadInt32(&count) != 1 {
+		t.Errorf("unexpected request count %d", count)
+	}
+}
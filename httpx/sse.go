@@ -0,0 +1,230 @@
+package httpx
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DefaultEventRetry = 3 * time.Second
+
+type Event struct {
+	ID    string
+	Event string
+	Data  string
+	Retry time.Duration
+}
+
+type EventFunc func(event *Event) error
+
+type eventReader struct {
+	reader *bufio.Reader
+	lastID string
+	retry  time.Duration
+}
+
+func newEventReader(reader io.Reader) *eventReader {
+
+	return &eventReader{
+		reader: bufio.NewReader(reader),
+	}
+}
+
+func (r *eventReader) next() (*Event, error) {
+
+	var (
+		data      strings.Builder
+		hasData   bool
+		eventType string
+	)
+
+	for {
+		line, err := r.reader.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line != "" {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		if line == "" {
+			if !hasData {
+				eventType = ""
+				continue
+			}
+			return &Event{
+				ID:    r.lastID,
+				Event: eventType,
+				Data:  data.String(),
+				Retry: r.retry,
+			}, nil
+		}
+
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value := line, ""
+		if index := strings.Index(line, ":"); index >= 0 {
+			field, value = line[:index], strings.TrimPrefix(line[index+1:], " ")
+		}
+
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			if hasData {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+			hasData = true
+		case "id":
+			if !strings.Contains(value, "\x00") {
+				r.lastID = value
+			}
+		case "retry":
+			if millis, err := strconv.ParseInt(value, 10, 64); err == nil && millis >= 0 {
+				r.retry = time.Duration(millis) * time.Millisecond
+			}
+		}
+	}
+}
+
+func Subscribe(ctx context.Context, path string, query url.Values, headers map[string][]string, fn EventFunc) error {
+
+	return DefaultClient.Subscribe(ctx, path, query, headers, fn)
+}
+
+func Events(ctx context.Context, path string, query url.Values, headers map[string][]string) (<-chan *Event, <-chan error) {
+
+	return DefaultClient.Events(ctx, path, query, headers)
+}
+
+func (client *HttpClient) Subscribe(ctx context.Context, path string, query url.Values, headers map[string][]string, fn EventFunc) error {
+
+	lastID := ""
+	retry := DefaultEventRetry
+	for {
+		reconnect, err := client.subscribe(ctx, path, query, headers, fn, &lastID, &retry)
+		if err == ErrStreamStop {
+			return nil
+		}
+
+		if !reconnect {
+			return err
+		}
+
+		timer := time.NewTimer(retry)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (client *HttpClient) Events(ctx context.Context, path string, query url.Values, headers map[string][]string) (<-chan *Event, <-chan error) {
+
+	events := make(chan *Event)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(events)
+		defer close(errs)
+		err := client.Subscribe(ctx, path, query, headers, func(event *Event) error {
+			select {
+			case events <- event:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil {
+			errs <- err
+		}
+	}()
+	return events, errs
+}
+
+func (client *HttpClient) subscribe(ctx context.Context, path string, query url.Values, headers map[string][]string, fn EventFunc, lastID *string, retry *time.Duration) (bool, error) {
+
+	eventHeaders := make(map[string][]string)
+	for key, value := range headers {
+		eventHeaders[key] = value
+	}
+
+	eventHeaders["Accept"] = []string{"text/event-stream"}
+	eventHeaders["Cache-Control"] = []string{"no-cache"}
+	if *lastID != "" {
+		eventHeaders["Last-Event-ID"] = []string{*lastID}
+	}
+
+	resp, err := client.Get(WithStatusError(ctx, false), path, query, eventHeaders)
+	if err != nil {
+		return ctx.Err() == nil && transientError(err), err
+	}
+
+	defer resp.body.Close()
+	statusCode := resp.StatusCode()
+	if statusCode == http.StatusNoContent {
+		return false, nil
+	}
+
+	if statusCode != http.StatusOK {
+		err := fmt.Errorf("client subscribe fail %d, %s", statusCode, resp.Status())
+		return statusCode >= http.StatusInternalServerError, err
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header("Content-Type")); mediaType != "text/event-stream" {
+		return false, fmt.Errorf("client subscribe content type invalid, %s", resp.Header("Content-Type"))
+	}
+
+	reader := newEventReader(resp.body)
+	reader.lastID = *lastID
+	reader.retry = *retry
+	for {
+		event, err := reader.next()
+		*lastID = reader.lastID
+		if reader.retry > 0 {
+			*retry = reader.retry
+		}
+
+		if err != nil {
+			return ctx.Err() == nil, err
+		}
+
+		if err := fn(event); err != nil {
+			return false, err
+		}
+	}
+}
+
+func transientError(err error) bool {
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return !dnsErr.IsNotFound
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return opErr.Op != "remote error"
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
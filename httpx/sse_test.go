@@ -0,0 +1,76 @@
+package httpx
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSubscribeReconnect(t *testing.T) {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		switch atomic.AddInt32(&count, 1) {
+		case 1:
+			if r.Header.Get("Last-Event-ID") != "" {
+				t.Errorf("unexpected last event id %q", r.Header.Get("Last-Event-ID"))
+			}
+			fmt.Fprint(w, "retry: 10\nid: 1\ndata: humpback\n\n")
+		default:
+			if r.Header.Get("Last-Event-ID") != "1" {
+				t.Errorf("unexpected last event id %q", r.Header.Get("Last-Event-ID"))
+			}
+			fmt.Fprint(w, "id: 2\nevent: update\ndata: gounits\n\n")
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events := []*Event{}
+	err := NewClient().Subscribe(ctx, server.URL, nil, nil, func(event *Event) error {
+		events = append(events, event)
+		if len(events) == 2 {
+			return ErrStreamStop
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if events[0].ID != "1" || events[0].Data != "humpback" || events[0].Retry != 10*time.Millisecond {
+		t.Errorf("unexpected first event %+v", events[0])
+	}
+	if events[1].ID != "2" || events[1].Event != "update" || events[1].Data != "gounits" {
+		t.Errorf("unexpected second event %+v", events[1])
+	}
+	if atomic.LoadInt32(&count) != 2 {
+		t.Errorf("unexpected connection count %d", count)
+	}
+}
+
+func TestSubscribePermanentError(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+	}))
+	server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, rawurl := range []string{server.URL, "ftp://127.0.0.1/events", "http://[::1/events"} {
+		events, errs := NewClient().Events(ctx, rawurl, nil, nil)
+		for range events {
+		}
+		if err := <-errs; err == nil || ctx.Err() != nil {
+			t.Errorf("%s expected permanent error, %v", rawurl, err)
+		}
+	}
+}
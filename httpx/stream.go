@@ -0,0 +1,132 @@
+package httpx
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+)
+
+var ErrStreamStop = errors.New("client stream stop.")
+
+type StreamFunc func(raw json.RawMessage) error
+
+func (resp *HttpResponse) Stream(fn StreamFunc) error {
+
+	err := resp.stream(fn)
+	if err == ErrStreamStop {
+		return nil
+	}
+	return err
+}
+
+func (resp *HttpResponse) stream(fn StreamFunc) error {
+
+	mediaType, _, _ := mime.ParseMediaType(resp.header.Get("Content-Type"))
+	if mediaType == "text/event-stream" {
+		return streamEvents(resp.body, fn)
+	}
+
+	reader := bufio.NewReader(resp.body)
+	first, err := peekNonSpace(reader)
+	if err != nil {
+		if err == io.EOF {
+			return nil
+		}
+		return err
+	}
+
+	if first == '[' {
+		return streamArray(reader, fn)
+	}
+	return streamValues(reader, fn)
+}
+
+func streamEvents(reader io.Reader, fn StreamFunc) error {
+
+	events := newEventReader(reader)
+	for {
+		event, err := events.next()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		data := strings.TrimSpace(event.Data)
+		if data == "" {
+			continue
+		}
+
+		if err := fn(json.RawMessage(data)); err != nil {
+			return err
+		}
+	}
+}
+
+func streamArray(reader io.Reader, fn StreamFunc) error {
+
+	dec := json.NewDecoder(reader)
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+
+		if err := fn(raw); err != nil {
+			return err
+		}
+	}
+
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if delim, ok := token.(json.Delim); !ok || delim != ']' {
+		return fmt.Errorf("client stream array invalid, %v", token)
+	}
+	return nil
+}
+
+func streamValues(reader io.Reader, fn StreamFunc) error {
+
+	dec := json.NewDecoder(reader)
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		if err := fn(raw); err != nil {
+			return err
+		}
+	}
+}
+
+func peekNonSpace(reader *bufio.Reader) (byte, error) {
+
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return b, reader.UnreadByte()
+	}
+}
@@ -0,0 +1,50 @@
+package httpx
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+	bodies := map[string]string{
+		"application/x-ndjson": "{\"id\":1}\n{\"id\":2}\n\n{\"id\":3}\n",
+		"application/json":     " [{\"id\":1}, {\"id\":2}, {\"id\":3}]",
+		"text/event-stream":    ": keepalive\nid: 1\ndata: {\"id\":1}\n\nevent: status\ndata: {\"id\":2}\n\ndata: {\"id\":\r\ndata: 3}\r\n\r\n",
+	}
+
+	for contentType, body := range bodies {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", contentType)
+			w.Write([]byte(body))
+		}))
+
+		resp, err := NewClient().Get(context.Background(), server.URL, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ids := []int{}
+		err = resp.Stream(func(raw json.RawMessage) error {
+			value := struct {
+				ID int `json:"id"`
+			}{}
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return err
+			}
+			ids = append(ids, value.ID)
+			return nil
+		})
+		resp.Close()
+		server.Close()
+
+		if err != nil {
+			t.Errorf("%s: %s", contentType, err)
+		}
+		if len(ids) != 3 || ids[0] != 1 || ids[1] != 2 || ids[2] != 3 {
+			t.Errorf("%s: unexpected ids %v", contentType, ids)
+		}
+	}
+}
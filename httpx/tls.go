@@ -0,0 +1,273 @@
+package httpx
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+var ErrCertificatePin = errors.New("client certificate pin mismatch.")
+
+func LoadCertPool(files ...string) (*x509.CertPool, error) {
+
+	pool := x509.NewCertPool()
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("client ca file invalid, %s", file)
+		}
+	}
+	return pool, nil
+}
+
+func SPKIHash(cert *x509.Certificate) string {
+
+	hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+func PinSPKI(config *tls.Config, pins ...string) *tls.Config {
+
+	if config == nil {
+		config = &tls.Config{}
+	} else {
+		config = config.Clone()
+	}
+
+	allowed := make(map[string]bool)
+	for _, pin := range pins {
+		allowed[pin] = true
+	}
+
+	verify := config.VerifyConnection
+	config.VerifyConnection = func(state tls.ConnectionState) error {
+		if verify != nil {
+			if err := verify(state); err != nil {
+				return err
+			}
+		}
+
+		for _, chain := range state.VerifiedChains {
+			for _, cert := range chain {
+				if allowed[SPKIHash(cert)] {
+					return nil
+				}
+			}
+		}
+		return ErrCertificatePin
+	}
+	return config
+}
+
+type CertReloader struct {
+	sync.RWMutex
+	caFiles    []string
+	certFile   string
+	keyFile    string
+	pool       *x509.CertPool
+	cert       *tls.Certificate
+	modtimes   map[string]time.Time
+	stop       chan struct{}
+	once       sync.Once
+	notify     []func()
+	ServerName string
+	OnReload   func(err error)
+}
+
+func NewCertReloader(caFile string, certFile string, keyFile string, interval time.Duration) (*CertReloader, error) {
+
+	reloader := &CertReloader{
+		caFiles:  []string{},
+		certFile: certFile,
+		keyFile:  keyFile,
+		modtimes: make(map[string]time.Time),
+		stop:     make(chan struct{}),
+		notify:   []func(){},
+	}
+
+	if caFile != "" {
+		reloader.caFiles = append(reloader.caFiles, caFile)
+	}
+
+	if err := reloader.Reload(); err != nil {
+		return nil, err
+	}
+
+	if interval > 0 {
+		go reloader.watch(interval)
+	}
+	return reloader, nil
+}
+
+func (reloader *CertReloader) Reload() error {
+
+	var (
+		pool *x509.CertPool
+		cert *tls.Certificate
+	)
+
+	modtimes := reloader.readModTimes()
+	if len(reloader.caFiles) > 0 {
+		p, err := LoadCertPool(reloader.caFiles...)
+		if err != nil {
+			return err
+		}
+		pool = p
+	}
+
+	if reloader.certFile != "" && reloader.keyFile != "" {
+		c, err := tls.LoadX509KeyPair(reloader.certFile, reloader.keyFile)
+		if err != nil {
+			return err
+		}
+		cert = &c
+	}
+
+	reloader.Lock()
+	reloader.pool = pool
+	reloader.cert = cert
+	reloader.modtimes = modtimes
+	notify := append([]func(){}, reloader.notify...)
+	reloader.Unlock()
+	for _, fn := range notify {
+		fn()
+	}
+	return nil
+}
+
+func (reloader *CertReloader) Close() {
+
+	reloader.once.Do(func() {
+		close(reloader.stop)
+	})
+}
+
+func (reloader *CertReloader) TLSConfig() *tls.Config {
+
+	config := &tls.Config{
+		RootCAs:    reloader.rootCAs(),
+		ServerName: reloader.ServerName,
+		GetClientCertificate: func(info *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			reloader.RLock()
+			defer reloader.RUnlock()
+			if reloader.cert == nil {
+				return &tls.Certificate{}, nil
+			}
+			return reloader.cert, nil
+		},
+	}
+
+	return config
+}
+
+func (reloader *CertReloader) rootCAs() *x509.CertPool {
+
+	reloader.RLock()
+	defer reloader.RUnlock()
+	return reloader.pool
+}
+
+func (reloader *CertReloader) watch(interval time.Duration) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-reloader.stop:
+			return
+		case <-ticker.C:
+			if !reloader.changed() {
+				continue
+			}
+
+			err := reloader.Reload()
+			if reloader.OnReload != nil {
+				reloader.OnReload(err)
+			}
+		}
+	}
+}
+
+func (reloader *CertReloader) changed() bool {
+
+	modtimes := reloader.readModTimes()
+	reloader.RLock()
+	defer reloader.RUnlock()
+	for file, modtime := range modtimes {
+		if !reloader.modtimes[file].Equal(modtime) {
+			return true
+		}
+	}
+	return false
+}
+
+func (reloader *CertReloader) readModTimes() map[string]time.Time {
+
+	modtimes := make(map[string]time.Time)
+	files := append([]string{reloader.certFile, reloader.keyFile}, reloader.caFiles...)
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+
+		if info, err := os.Stat(file); err == nil {
+			modtimes[file] = info.ModTime()
+		}
+	}
+	return modtimes
+}
+
+func (client *HttpClient) SetCertReloader(reloader *CertReloader) (*HttpClient, error) {
+
+	err := client.updateTransport(func(transport *http.Transport) {
+		transport.TLSClientConfig = reloader.TLSConfig()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reloader.Lock()
+	reloader.notify = append(reloader.notify, func() {
+		reloader.apply(client)
+	})
+	reloader.Unlock()
+	return client, nil
+}
+
+func (reloader *CertReloader) apply(client *HttpClient) {
+
+	previous := client.GetTransport()
+	pool := reloader.rootCAs()
+	client.updateTransport(func(transport *http.Transport) {
+		config := transport.TLSClientConfig.Clone()
+		if config == nil {
+			config = reloader.TLSConfig()
+		}
+		config.RootCAs = pool
+		transport.TLSClientConfig = config
+	})
+	previous.CloseIdleConnections()
+}
+
+func (client *HttpClient) SetPinnedSPKI(pins ...string) (*HttpClient, error) {
+
+	err := client.updateTransport(func(transport *http.Transport) {
+		transport.TLSClientConfig = PinSPKI(transport.TLSClientConfig, pins...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
@@ -0,0 +1,196 @@
+package httpx
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestCert(t *testing.T, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func TestPinnedSPKIVerifiedChain(t *testing.T) {
+	ca, caKey := newTestCert(t, "ca", nil, nil)
+	leaf, leafKey := newTestCert(t, "leaf", ca, caKey)
+	other, _ := newTestCert(t, "other", nil, nil)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{leaf.Raw, other.Raw}, PrivateKey: leafKey}},
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	client, err := NewClient().SetTLSClientConfig(&tls.Config{RootCAs: roots}).SetPinnedSPKI(SPKIHash(other))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Get(context.Background(), server.URL, nil, nil); !errors.Is(err, ErrCertificatePin) {
+		t.Errorf("unexpected pin error %v", err)
+	}
+
+	client, err = NewClient().SetTLSClientConfig(&tls.Config{RootCAs: roots}).SetPinnedSPKI(SPKIHash(ca))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get(context.Background(), server.URL, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Close()
+}
+
+func TestCertReloaderServerName(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "httpx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	caFile := filepath.Join(dir, "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := ioutil.WriteFile(caFile, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reloader, err := NewCertReloader(caFile, "", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reloader.Close()
+
+	client, err := NewClient().SetCertReloader(reloader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config := http.DefaultTransport.(*http.Transport).TLSClientConfig; config != nil && config.RootCAs != nil {
+		t.Errorf("unexpected default transport tls config")
+	}
+	resp, err := client.Get(context.Background(), server.URL, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Close()
+
+	reloader.ServerName = "example.com"
+	if client, err = NewClient().SetCertReloader(reloader); err != nil {
+		t.Fatal(err)
+	}
+	resp, err = client.Get(context.Background(), server.URL, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Close()
+
+	reloader.ServerName = "humpback.io"
+	if client, err = NewClient().SetCertReloader(reloader); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Get(context.Background(), server.URL, nil, nil); err == nil {
+		t.Errorf("expected hostname mismatch error")
+	}
+}
+
+func TestCertReloaderReload(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "httpx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	other, _ := newTestCert(t, "other", nil, nil)
+	caFile := filepath.Join(dir, "ca.pem")
+	if err := ioutil.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: other.Raw}), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reloader, err := NewCertReloader(caFile, "", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reloader.Close()
+
+	client, err := NewClient().SetCertReloader(reloader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Get(context.Background(), server.URL, nil, nil); err == nil {
+		t.Errorf("expected unknown authority error")
+	}
+
+	if err := ioutil.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := reloader.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get(context.Background(), server.URL, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Close()
+}
@@ -0,0 +1,129 @@
+package httpx
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+type Timings struct {
+	DNS          time.Duration
+	Connect      time.Duration
+	TLSHandshake time.Duration
+	FirstByte    time.Duration
+	Total        time.Duration
+	Reused       bool
+	RemoteAddr   string
+}
+
+type TraceFunc func(method string, rawurl string, timings Timings, err error)
+
+type requestTrace struct {
+	sync.Mutex
+	start     time.Time
+	attempt   time.Time
+	dnsStart  time.Time
+	connStart time.Time
+	tlsStart  time.Time
+	timings   Timings
+}
+
+func (client *HttpClient) SetTraceHook(hook TraceFunc) *HttpClient {
+
+	return client.update(func(config *clientConfig) {
+		config.tracehook = hook
+	})
+}
+
+func WithTraceHook(ctx context.Context, hook TraceFunc) context.Context {
+
+	return context.WithValue(ctx, traceHookKey, hook)
+}
+
+func (config *clientConfig) traceHook(ctx context.Context) TraceFunc {
+
+	if hook, ok := ctx.Value(traceHookKey).(TraceFunc); ok {
+		return hook
+	}
+	return config.tracehook
+}
+
+func withRequestTrace(ctx context.Context) (context.Context, *requestTrace) {
+
+	trace := &requestTrace{
+		start: time.Now(),
+	}
+
+	clientTrace := &httptrace.ClientTrace{
+		GetConn: func(hostPort string) {
+			trace.Lock()
+			trace.attempt = time.Now()
+			trace.timings = Timings{}
+			trace.Unlock()
+		},
+		DNSStart: func(info httptrace.DNSStartInfo) {
+			trace.Lock()
+			trace.dnsStart = time.Now()
+			trace.Unlock()
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			trace.Lock()
+			trace.timings.DNS = time.Since(trace.dnsStart)
+			trace.Unlock()
+		},
+		ConnectStart: func(network string, addr string) {
+			trace.Lock()
+			trace.connStart = time.Now()
+			trace.Unlock()
+		},
+		ConnectDone: func(network string, addr string, err error) {
+			trace.Lock()
+			if err == nil {
+				trace.timings.Connect = time.Since(trace.connStart)
+			}
+			trace.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			trace.Lock()
+			trace.tlsStart = time.Now()
+			trace.Unlock()
+		},
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			trace.Lock()
+			trace.timings.TLSHandshake = time.Since(trace.tlsStart)
+			trace.Unlock()
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			trace.Lock()
+			trace.timings.Reused = info.Reused
+			if info.Conn != nil {
+				trace.timings.RemoteAddr = info.Conn.RemoteAddr().String()
+			}
+			trace.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			trace.Lock()
+			trace.timings.FirstByte = time.Since(trace.attempt)
+			trace.Unlock()
+		},
+	}
+	return httptrace.WithClientTrace(ctx, clientTrace), trace
+}
+
+func (trace *requestTrace) done() Timings {
+
+	trace.Lock()
+	defer trace.Unlock()
+	trace.timings.Total = time.Since(trace.start)
+	return trace.timings
+}
+
+func (config *clientConfig) emitTrace(ctx context.Context, request *http.Request, timings Timings, err error) {
+
+	if hook := config.traceHook(ctx); hook != nil {
+		hook(request.Method, request.URL.String(), timings, err)
+	}
+}
@@ -0,0 +1,50 @@
+package httpx
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTraceTimings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	traced := []Timings{}
+	client := NewClient().SetTraceHook(func(method string, rawurl string, timings Timings, err error) {
+		if method != http.MethodGet || rawurl != server.URL || err != nil {
+			t.Errorf("unexpected trace %s %s %v", method, rawurl, err)
+		}
+		traced = append(traced, timings)
+	})
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(context.Background(), server.URL, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ioutil.ReadAll(resp.Body())
+		resp.Close()
+
+		timings := resp.Timings()
+		if timings.FirstByte < 10*time.Millisecond || timings.Total < timings.FirstByte {
+			t.Errorf("unexpected timings %+v", timings)
+		}
+		if timings.RemoteAddr != server.Listener.Addr().String() {
+			t.Errorf("unexpected remote addr %s", timings.RemoteAddr)
+		}
+		if timings.Reused != (i > 0) {
+			t.Errorf("unexpected connection reuse %v", timings.Reused)
+		}
+	}
+
+	if len(traced) != 2 {
+		t.Errorf("unexpected trace count %d", len(traced))
+	}
+}
@@ -0,0 +1,111 @@
+package httpx
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const UnixScheme = "unix"
+
+var unixTransport = &http.Transport{
+	DialContext:           dialUnix,
+	DisableKeepAlives:     false,
+	MaxIdleConns:          http.DefaultTransport.(*http.Transport).MaxIdleConns,
+	MaxIdleConnsPerHost:   http.DefaultMaxIdleConnsPerHost,
+	IdleConnTimeout:       120 * time.Second,
+	ExpectContinueTimeout: http.DefaultTransport.(*http.Transport).ExpectContinueTimeout,
+}
+
+func NewUnixClient(socketPath string) *HttpClient {
+
+	return NewClient().SetBaseURL(UnixURL(socketPath, ""))
+}
+
+func UnixURL(socketPath string, path string) string {
+
+	rawurl := &url.URL{
+		Scheme: UnixScheme,
+		Path:   socketPath + ":" + path,
+	}
+	return rawurl.String()
+}
+
+func dialUnix(ctx context.Context, network string, addr string) (net.Conn, error) {
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	socketPath, err := hex.DecodeString(host)
+	if err != nil {
+		return nil, errors.New("client unix socket address invalid.")
+	}
+
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+	}
+	return dialer.DialContext(ctx, "unix", string(socketPath))
+}
+
+func unixSocket(rawurl *url.URL) (string, string, error) {
+
+	escaped := rawurl.EscapedPath()
+	socketPath, path := escaped, "/"
+	if i := strings.Index(escaped, ":"); i >= 0 {
+		socketPath, path = escaped[:i], escaped[i+1:]
+	}
+
+	socketPath, err := url.PathUnescape(socketPath)
+	if err != nil || socketPath == "" {
+		return "", "", errors.New("client unix socket path invalid.")
+	}
+
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return socketPath, path, nil
+}
+
+func unixHost(rawurl *url.URL) string {
+
+	if rawurl.Scheme != UnixScheme {
+		return rawurl.Host
+	}
+
+	socketPath, _, err := unixSocket(rawurl)
+	if err != nil {
+		return rawurl.Host
+	}
+	return UnixScheme + ":" + socketPath
+}
+
+func unixRequest(request *http.Request, c *http.Client) (*http.Request, *http.Client, error) {
+
+	socketPath, path, err := unixSocket(request.URL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rawurl, err := url.Parse("http://" + hex.EncodeToString([]byte(socketPath)) + path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rawurl.RawQuery = request.URL.RawQuery
+	unixRequest := request.Clone(request.Context())
+	unixRequest.URL = rawurl
+	if unixRequest.Host == "" {
+		unixRequest.Host = "localhost"
+	}
+
+	unixClient := *c
+	unixClient.Transport = unixTransport
+	return unixRequest, &unixClient, nil
+}
@@ -0,0 +1,76 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUnixClient(t *testing.T) {
+	dir, err := ioutil.TempDir("", "httpx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	socketPath := filepath.Join(dir, "agent.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{\"path\":\"" + r.URL.Path + "\",\"all\":\"" + r.URL.Query().Get("all") + "\"}"))
+	})}
+	go server.Serve(listener)
+	defer server.Close()
+
+	value := map[string]string{}
+	resp, err := NewUnixClient(socketPath).Get(context.Background(), "/containers/json", map[string][]string{"all": {"1"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+	if err := resp.JSON(&value); err != nil {
+		t.Fatal(err)
+	}
+	if value["path"] != "/containers/json" || value["all"] != "1" {
+		t.Errorf("unexpected response %v", value)
+	}
+
+	resp, err = Get(context.Background(), UnixURL(socketPath, "/_ping"), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+	if err := resp.JSON(&value); err != nil {
+		t.Fatal(err)
+	}
+	if value["path"] != "/_ping" {
+		t.Errorf("unexpected response %v", value)
+	}
+
+	if rawurl := UnixURL(socketPath, "/_ping"); resp.RawURL() != rawurl {
+		t.Errorf("unexpected raw url %s", resp.RawURL())
+	}
+
+	client := NewUnixClient(socketPath).SetCircuitBreaker(DefaultBreakerOptions)
+	_, err = client.Get(WithStatusError(context.Background(), true), "/missing", nil, nil)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.URL != UnixURL(socketPath, "/missing") {
+		t.Errorf("unexpected status error %v", err)
+	}
+	if _, ret := client.load().breakers.hosts[UnixScheme+":"+socketPath]; !ret {
+		t.Errorf("unexpected breaker hosts %v", client.load().breakers.hosts)
+	}
+}